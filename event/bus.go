@@ -0,0 +1,219 @@
+package event
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Bus 按字符串主题派发的事件总线,供mod/脚本层使用
+// 主题以'.'分段,如"player.1001.died"
+// 订阅时可用通配: "*"匹配一段,"**"匹配零或多段,如"player.*.died","player.**"
+//
+//	bus := event.NewBus()
+//	event.Subscribe(bus, "player.*.died", func(topic string, p *Player) { ... })
+//	bus.Publish("player.1001.died", player)
+type Bus struct {
+	errorPolicy
+	mu        sync.RWMutex
+	subs      []*handler[busSub] //写时复制
+	published map[string]uint64  //发布过的主题及次数,SetPublishStats开启后才记录
+	statLimit int                //最多记录的主题数
+}
+
+type busSub struct {
+	pattern string
+	segs    []string
+	cb      reflect.Value                        //原始回调,用于错误信息
+	fn      func(topic string, payload any) bool //payload类型不符时返回false
+}
+
+// TopicInfo 主题统计
+type TopicInfo struct {
+	Topic       string
+	Subscribers int    //匹配该主题的订阅数
+	Published   uint64 //发布次数
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// SetPublishStats 记录发布过的主题及次数,供Topics查询,默认关闭
+// 主题常带id,最多记录limit个不同主题,之后的新主题不再记录;limit<=0时关闭并清空
+func (b *Bus) SetPublishStats(limit int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.statLimit = limit
+	if limit <= 0 {
+		b.published = nil
+	}
+}
+
+// Subscribe 订阅匹配pattern的主题,payload不能转为T的发布会被跳过
+func Subscribe[T any](b *Bus, pattern string, cb func(topic string, payload T)) *Subscription {
+	return SubscribePriority(b, pattern, PriorityNormal, cb)
+}
+
+// SubscribePriority 按优先级订阅,数值大的先执行
+func SubscribePriority[T any](b *Bus, pattern string, priority int, cb func(topic string, payload T)) *Subscription {
+	segs, err := splitPattern(pattern)
+	if err != nil {
+		panic(err)
+	}
+	sub := busSub{
+		pattern: pattern,
+		segs:    segs,
+		cb:      reflect.ValueOf(cb),
+		fn: func(topic string, payload any) bool {
+			p, ok := payload.(T)
+			if !ok && payload != nil {
+				return false
+			}
+			cb(topic, p)
+			return true
+		},
+	}
+	h := newHandler(sub, priority)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs = insertHandler(b.subs, h)
+	return &Subscription{cancel: func() { b.remove(h.id) }}
+}
+
+func (b *Bus) remove(id uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs = removeHandler(b.subs, func(h *handler[busSub]) bool { return h.id == id })
+}
+
+// Publish 发布到topic,返回实际调用的订阅数
+func (b *Bus) Publish(topic string, payload any) int {
+	segs := strings.Split(topic, ".")
+	b.mu.RLock()
+	subs, stats := b.subs, b.statLimit > 0
+	b.mu.RUnlock()
+	if stats {
+		b.countPublish(topic)
+	}
+
+	n := 0
+	for _, h := range subs {
+		if !matchTopic(h.fn.segs, segs) {
+			continue
+		}
+		if b.invoke(topic, h.fn, payload) {
+			n++
+		}
+	}
+	return n
+}
+
+func (b *Bus) countPublish(topic string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.published[topic]; !ok && len(b.published) >= b.statLimit {
+		return
+	}
+	if b.published == nil {
+		b.published = make(map[string]uint64)
+	}
+	b.published[topic]++
+}
+
+func (b *Bus) invoke(topic string, sub busSub, payload any) (called bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			called = true
+			b.recoverCallback(topic, sub.cb, r)
+		}
+	}()
+	return sub.fn(topic, payload)
+}
+
+// SubscriberCount 匹配topic的订阅数
+func (b *Bus) SubscriberCount(topic string) int {
+	segs := strings.Split(topic, ".")
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	n := 0
+	for _, h := range b.subs {
+		if matchTopic(h.fn.segs, segs) {
+			n++
+		}
+	}
+	return n
+}
+
+// Patterns 所有订阅的pattern及订阅数
+func (b *Bus) Patterns() map[string]int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	m := make(map[string]int)
+	for _, h := range b.subs {
+		m[h.fn.pattern]++
+	}
+	return m
+}
+
+// Topics 已知主题,包括发布过的主题(需SetPublishStats开启)和不含通配的订阅,按主题排序
+func (b *Bus) Topics() []TopicInfo {
+	b.mu.RLock()
+	known := make(map[string]uint64, len(b.published))
+	for topic, n := range b.published {
+		known[topic] = n
+	}
+	for _, h := range b.subs {
+		if !strings.Contains(h.fn.pattern, "*") {
+			known[h.fn.pattern] += 0
+		}
+	}
+	b.mu.RUnlock()
+
+	infos := make([]TopicInfo, 0, len(known))
+	for topic, n := range known {
+		infos = append(infos, TopicInfo{Topic: topic, Subscribers: b.SubscriberCount(topic), Published: n})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Topic < infos[j].Topic })
+	return infos
+}
+
+func splitPattern(pattern string) ([]string, error) {
+	segs := strings.Split(pattern, ".")
+	for _, s := range segs {
+		if s == "" {
+			return nil, fmt.Errorf("event: invalid topic pattern %q: empty segment", pattern)
+		}
+		if strings.Contains(s, "*") && s != "*" && s != "**" {
+			return nil, fmt.Errorf("event: invalid topic pattern %q: wildcard must be a whole segment", pattern)
+		}
+	}
+	return segs, nil
+}
+
+// matchTopic pattern各段是否匹配topic各段
+func matchTopic(pattern, topic []string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case "**":
+			for i := 0; i <= len(topic); i++ {
+				if matchTopic(pattern[1:], topic[i:]) {
+					return true
+				}
+			}
+			return false
+		case "*":
+			if len(topic) == 0 {
+				return false
+			}
+		default:
+			if len(topic) == 0 || pattern[0] != topic[0] {
+				return false
+			}
+		}
+		pattern, topic = pattern[1:], topic[1:]
+	}
+	return len(topic) == 0
+}
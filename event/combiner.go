@@ -0,0 +1,116 @@
+package event
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Combiner 合并各回调的返回值,作为Call的返回值
+// 合并只作用于第一个返回值,其余返回值(如error)取最后一个回调的
+type Combiner interface {
+	// Init 初始结果,没有回调或回调全部panic时即为Call的返回值
+	Init(zero []reflect.Value) []reflect.Value
+	// Combine 合并当前回调的返回值ret到acc,stop为true时短路后续回调
+	Combine(acc, ret []reflect.Value) (out []reflect.Value, stop bool)
+}
+
+var (
+	CombineLast         Combiner = combineLast{}          //最后一个回调的返回值(默认)
+	CombineFirstNonZero Combiner = combineFirstNonZero{}  //第一个非零值,之后的回调不再调用
+	CombineAll          Combiner = combineAll{}           //返回值为切片,拼接所有回调的结果
+	CombineAnd          Combiner = combineBool{and: true} //返回值为bool,全部为true才为true,遇false短路,无回调为true
+	CombineOr           Combiner = combineBool{}          //返回值为bool,任一为true即为true,遇true短路,无回调为false
+	CombineSum          Combiner = combineSum{}           //返回值为整数或浮点数,求和,用于数值修正叠加
+)
+
+// checkCombiner 无返回值的事件只能用CombineLast
+func checkCombiner(zero []reflect.Value, c Combiner) {
+	if c == nil || c == CombineLast {
+		return
+	}
+	if len(zero) == 0 {
+		panic("Combiner requires an event type with return values")
+	}
+	c.Init(zero) //类型不符时在设置时就panic
+}
+
+// replaceFirst 第一个返回值换成v,其余取ret的
+func replaceFirst(ret []reflect.Value, v reflect.Value) []reflect.Value {
+	out := make([]reflect.Value, len(ret))
+	copy(out, ret)
+	out[0] = v
+	return out
+}
+
+type combineLast struct{}
+
+func (combineLast) Init(zero []reflect.Value) []reflect.Value { return zero }
+func (combineLast) Combine(_, ret []reflect.Value) ([]reflect.Value, bool) {
+	return ret, false
+}
+
+type combineFirstNonZero struct{}
+
+func (combineFirstNonZero) Init(zero []reflect.Value) []reflect.Value { return zero }
+func (combineFirstNonZero) Combine(acc, ret []reflect.Value) ([]reflect.Value, bool) {
+	if len(ret) == 0 || ret[0].IsZero() {
+		return acc, false
+	}
+	return ret, true
+}
+
+type combineAll struct{}
+
+func (combineAll) Init(zero []reflect.Value) []reflect.Value {
+	if zero[0].Kind() != reflect.Slice {
+		panic(fmt.Sprintf("CombineAll: result type %s is not a slice", zero[0].Type()))
+	}
+	return zero
+}
+
+func (combineAll) Combine(acc, ret []reflect.Value) ([]reflect.Value, bool) {
+	return replaceFirst(ret, reflect.AppendSlice(acc[0], ret[0])), false
+}
+
+type combineBool struct {
+	and bool
+}
+
+func (c combineBool) Init(zero []reflect.Value) []reflect.Value {
+	if zero[0].Kind() != reflect.Bool {
+		panic(fmt.Sprintf("CombineAnd/CombineOr: result type %s is not bool", zero[0].Type()))
+	}
+	v := reflect.New(zero[0].Type()).Elem()
+	v.SetBool(c.and)
+	return replaceFirst(zero, v)
+}
+
+func (c combineBool) Combine(_, ret []reflect.Value) ([]reflect.Value, bool) {
+	return ret, ret[0].Bool() != c.and
+}
+
+type combineSum struct{}
+
+func (combineSum) Init(zero []reflect.Value) []reflect.Value {
+	switch zero[0].Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return zero
+	}
+	panic(fmt.Sprintf("CombineSum: result type %s is not numeric", zero[0].Type()))
+}
+
+func (combineSum) Combine(acc, ret []reflect.Value) ([]reflect.Value, bool) {
+	a, b := acc[0], ret[0]
+	v := reflect.New(a.Type()).Elem()
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(a.Int() + b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(a.Uint() + b.Uint())
+	default:
+		v.SetFloat(a.Float() + b.Float())
+	}
+	return replaceFirst(ret, v), false
+}
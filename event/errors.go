@@ -0,0 +1,139 @@
+package event
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+type ErrorMode int
+
+const (
+	PanicOnError ErrorMode = iota
+	LogErrors
+)
+
+// PanicError 回调panic时包装后交给错误处理,PanicOnError模式下以它重新panic
+type PanicError struct {
+	Event    string //事件名
+	Callback string //回调函数名及定义位置
+	Value    any    //recover到的值
+	Stack    []byte //panic时的调用栈
+}
+
+func (p *PanicError) Error() string {
+	return fmt.Sprintf("event %s: callback %s panic: %v\n%s", p.Event, p.Callback, p.Value, p.Stack)
+}
+
+// Unwrap panic值为error时可用errors.Is/As判断
+func (p *PanicError) Unwrap() error {
+	err, _ := p.Value.(error)
+	return err
+}
+
+var (
+	globalErrorMode    atomic.Int32
+	globalErrorHandler atomic.Pointer[func(err any)]
+)
+
+// handleByMode 按模式处理,不受自定义handler影响
+func handleByMode(mode ErrorMode, err any) {
+	switch mode {
+	case PanicOnError:
+		panic(err)
+	case LogErrors:
+		fmt.Printf("Event callback panic: %v\n", err)
+	}
+}
+
+// SetErrorMode 设置全局错误模式,未单独设置的事件使用
+func SetErrorMode(mode ErrorMode) {
+	globalErrorMode.Store(int32(mode))
+}
+
+// SetErrorHandler 设置全局错误处理,err为*PanicError,nil恢复为按ErrorMode处理
+func SetErrorHandler(handler func(err any)) {
+	if handler == nil {
+		globalErrorHandler.Store(nil)
+		return
+	}
+	globalErrorHandler.Store(&handler)
+}
+
+// errorPolicy 单个事件的错误处理,未设置时使用全局设置
+// 优先级: 事件handler > 事件mode > 全局handler > 全局mode
+type errorPolicy struct {
+	mode    atomic.Pointer[ErrorMode]
+	handler atomic.Pointer[func(err any)]
+}
+
+// SetErrorMode 设置本事件的错误模式
+func (p *errorPolicy) SetErrorMode(mode ErrorMode) {
+	p.mode.Store(&mode)
+}
+
+// SetErrorHandler 设置本事件的错误处理,err为*PanicError,nil恢复为使用全局设置
+func (p *errorPolicy) SetErrorHandler(handler func(err any)) {
+	if handler == nil {
+		p.handler.Store(nil)
+		return
+	}
+	p.handler.Store(&handler)
+}
+
+func (p *errorPolicy) handle(err *PanicError) {
+	if h := p.handler.Load(); h != nil {
+		(*h)(err)
+		return
+	}
+	if m := p.mode.Load(); m != nil {
+		handleByMode(*m, err)
+		return
+	}
+	if h := globalErrorHandler.Load(); h != nil {
+		(*h)(err)
+		return
+	}
+	handleByMode(ErrorMode(globalErrorMode.Load()), err)
+}
+
+// recoverCallback 在defer中调用,包装panic后交给错误处理,未重新panic时返回包装的错误
+func (p *errorPolicy) recoverCallback(event string, fn reflect.Value, r any) error {
+	err := &PanicError{Event: event, Callback: funcName(fn), Value: r, Stack: debug.Stack()}
+	p.handle(err)
+	return err
+}
+
+// funcName 回调的函数名及定义位置
+func funcName(fn reflect.Value) string {
+	f := runtime.FuncForPC(fn.Pointer())
+	if f == nil {
+		return fn.Type().String()
+	}
+	file, line := f.FileLine(f.Entry())
+	return fmt.Sprintf("%s (%s:%d)", f.Name(), file, line)
+}
+
+// joinErrors 收集的error,单个时原样返回,多个时errors.Join
+func joinErrors(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
+
+// callerName 事件定义位置,作为默认事件名
+func callerName(skip int, fnType reflect.Type) string {
+	_, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return fnType.String()
+	}
+	return fmt.Sprintf("%s@%s/%s:%d", fnType, filepath.Base(filepath.Dir(file)), filepath.Base(file), line)
+}
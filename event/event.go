@@ -1,58 +1,264 @@
 package event
 
 import (
-	"fmt"
+	"errors"
 	"reflect"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
-type ErrorMode int
-
+// 回调优先级,数值大的先执行,同优先级按注册顺序执行
 const (
-	PanicOnError ErrorMode = iota
-	LogErrors
+	PriorityLowest  = -200
+	PriorityLow     = -100
+	PriorityNormal  = 0
+	PriorityHigh    = 100
+	PriorityHighest = 200
 )
 
-var (
-	defaultErrorHandler = func(err any) {
-		switch errorMode {
-		case PanicOnError:
-			panic(err)
-		case LogErrors:
-			fmt.Printf("Event callback panic: %v\n", err)
+// ErrStop 回调最后一个返回值为error且errors.Is(err, ErrStop)时,中止后续回调的派发
+var ErrStop = errors.New("event: stop propagation")
+
+var errorType = reflect.TypeFor[error]()
+
+var handlerID atomic.Uint64
+
+// handler 已注册的回调,F为reflect.Value或类型化事件的func
+type handler[F any] struct {
+	id       uint64
+	fn       F
+	priority int
+	once     bool        //RegOnce注册的,只触发一次
+	fired    atomic.Bool //once回调已触发
+	label    atomic.Pointer[string]
+}
+
+func newHandler[F any](fn F, priority int) *handler[F] {
+	return &handler[F]{id: handlerID.Add(1), fn: fn, priority: priority}
+}
+
+// fire once回调只允许触发一次,返回false表示应跳过
+func (h *handler[F]) fire() bool {
+	return !h.once || h.fired.CompareAndSwap(false, true)
+}
+
+// name 回调函数名及定义位置,首次使用时计算并缓存
+func (h *handler[F]) name() string {
+	if p := h.label.Load(); p != nil {
+		return *p
+	}
+	fn, ok := any(h.fn).(reflect.Value)
+	if !ok {
+		fn = reflect.ValueOf(h.fn)
+	}
+	name := funcName(fn)
+	h.label.Store(&name)
+	return name
+}
+
+// insertHandler 按优先级降序插入,同优先级插在末尾保持注册顺序
+// 写时复制,不修改原切片,派发中持有的快照不受影响
+func insertHandler[F any](hs []*handler[F], h *handler[F]) []*handler[F] {
+	i := sort.Search(len(hs), func(i int) bool { return hs[i].priority < h.priority })
+	out := make([]*handler[F], 0, len(hs)+1)
+	out = append(out, hs[:i]...)
+	out = append(out, h)
+	return append(out, hs[i:]...)
+}
+
+// removeHandler 移除第一个满足match的回调,保持顺序,写时复制
+func removeHandler[F any](hs []*handler[F], match func(h *handler[F]) bool) []*handler[F] {
+	for i, h := range hs {
+		if match(h) {
+			out := make([]*handler[F], 0, len(hs)-1)
+			out = append(out, hs[:i]...)
+			return append(out, hs[i+1:]...)
 		}
 	}
+	return hs
+}
 
-	errorMode    ErrorMode     = PanicOnError
-	errorHandler func(err any) = defaultErrorHandler
-)
+// Subscription 注册句柄,用于注销闭包回调
+type Subscription struct {
+	once   sync.Once
+	cancel func()
+}
+
+// Cancel 注销对应回调,可重复调用
+func (s *Subscription) Cancel() {
+	if s == nil {
+		return
+	}
+	s.once.Do(s.cancel)
+}
+
+// isStop 回调返回值是否要求中止派发
+func isStop(ret []reflect.Value) bool {
+	if len(ret) == 0 {
+		return false
+	}
+	last := ret[len(ret)-1]
+	if last.Type() != errorType || last.IsNil() {
+		return false
+	}
+	return errors.Is(last.Interface().(error), ErrStop)
+}
 
 type EventType[T any] struct {
-	mu   sync.RWMutex
-	cbs  []reflect.Value //调用时用,Reg时反射过的funcs
-	Call T
+	errorPolicy
+	tracing
+	name   string
+	mu     sync.RWMutex
+	cbs    []*handler[reflect.Value]                  //调用时用,Reg时反射过的funcs,按优先级排好序,写时复制
+	q      queue                                      //Post入队,Flush或worker派发
+	zero   []reflect.Value                            //零值返回
+	comb   Combiner                                   //返回值合并方式,nil为CombineLast
+	record atomic.Pointer[func(args []reflect.Value)] //Record录制
+	Call   T                                          //同步派发
+	Post   T                                          //入队,返回零值
+}
+
+// Name 事件名,默认为类型和定义位置
+func (e *EventType[T]) Name() string {
+	return e.name
 }
 
-func (e *EventType[T]) Reg(cb T) {
+// SetName 设置事件名,用于错误信息和调试,应在使用前设置
+func (e *EventType[T]) SetName(name string) *EventType[T] {
+	e.name = name
+	return e
+}
+
+// SetCombiner 设置返回值合并方式,nil恢复为CombineLast
+func (e *EventType[T]) SetCombiner(c Combiner) {
+	checkCombiner(e.zero, c)
 	e.mu.Lock()
 	defer e.mu.Unlock()
-	e.cbs = append(e.cbs, reflect.ValueOf(cb))
+	e.comb = c
 }
+
+func (e *EventType[T]) Reg(cb T) *Subscription {
+	return e.RegPriority(cb, PriorityNormal)
+}
+
+// RegPriority 按优先级注册,数值大的先执行
+func (e *EventType[T]) RegPriority(cb T, priority int) *Subscription {
+	return e.add(newHandler(reflect.ValueOf(cb), priority))
+}
+
+// RegOnce 注册只触发一次的回调,触发后自动注销
+func (e *EventType[T]) RegOnce(cb T) *Subscription {
+	h := newHandler(reflect.ValueOf(cb), PriorityNormal)
+	h.once = true
+	return e.add(h)
+}
+
+func (e *EventType[T]) add(h *handler[reflect.Value]) *Subscription {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.cbs = insertHandler(e.cbs, h)
+	return &Subscription{cancel: func() { e.remove(h.id) }}
+}
+
+func (e *EventType[T]) remove(id uint64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.cbs = removeHandler(e.cbs, func(h *handler[reflect.Value]) bool { return h.id == id })
+}
+
+// UnReg 按函数指针注销,同一函数字面量生成的多个闭包无法区分,闭包请用Reg返回的Subscription注销
 func (e *EventType[T]) UnReg(cb T) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
 	targetPtr := reflect.ValueOf(cb).Pointer()
-	for i, registered := range e.cbs {
-		if registered.Pointer() == targetPtr {
-			lastIdx := len(e.cbs) - 1
-			if i < lastIdx {
-				e.cbs[i] = e.cbs[lastIdx]
+	e.cbs = removeHandler(e.cbs, func(h *handler[reflect.Value]) bool { return h.fn.Pointer() == targetPtr })
+}
+
+// snapshot 派发用的回调快照,派发期间可安全地Reg/UnReg
+func (e *EventType[T]) snapshot() ([]*handler[reflect.Value], Combiner) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.comb == nil {
+		return e.cbs, CombineLast
+	}
+	return e.cbs, e.comb
+}
+
+func (e *EventType[T]) dispatch(args []reflect.Value) []reflect.Value {
+	if rec := e.record.Load(); rec != nil {
+		(*rec)(args)
+	}
+	cbs, comb := e.snapshot()
+	var fired []uint64 // 已触发的once回调
+	defer func() {
+		for _, id := range fired {
+			e.remove(id)
+		}
+	}()
+	return callHandlers(e.name, &e.errorPolicy, e.current(), cbs, comb, e.zero, args, &fired)
+}
+
+// callHandlers 按顺序调用回调,合并返回值
+// 最后一个返回值为error时,收集所有回调返回的error及recover的panic,单个原样返回,多个errors.Join
+// tracer非nil时上报派发和每个回调的耗时
+func callHandlers(name string, policy *errorPolicy, tracer Tracer, cbs []*handler[reflect.Value], comb Combiner,
+	zero, args []reflect.Value, fired *[]uint64) []reflect.Value {
+	if tracer != nil {
+		tracer.DispatchStart(name, len(cbs))
+		start := time.Now()
+		defer func() { tracer.DispatchEnd(name, time.Since(start)) }()
+	}
+	ret := comb.Init(zero)
+	hasErr := len(zero) > 0 && zero[len(zero)-1].Type() == errorType
+	var errs []error
+	for _, cb := range cbs {
+		if !cb.fire() {
+			continue
+		}
+		if cb.once {
+			*fired = append(*fired, cb.id)
+		}
+		stop := false
+		func() {
+			var start time.Time
+			if tracer != nil {
+				start = time.Now()
 			}
-			e.cbs = e.cbs[:lastIdx]
-			return
+			defer func() {
+				r := recover()
+				if tracer != nil {
+					tracer.Callback(name, cb.name(), time.Since(start), r != nil)
+				}
+				if r != nil {
+					if err := policy.recoverCallback(name, cb.fn, r); hasErr {
+						errs = append(errs, err)
+					}
+				}
+			}()
+			r := cb.fn.Call(args)
+			ret, stop = comb.Combine(ret, r)
+			stop = stop || isStop(r)
+			if hasErr && !r[len(r)-1].IsNil() {
+				errs = append(errs, r[len(r)-1].Interface().(error))
+			}
+		}()
+		if stop {
+			break
+		}
+	}
+	if hasErr {
+		out := make([]reflect.Value, len(ret))
+		copy(out, ret)
+		out[len(out)-1] = zero[len(zero)-1]
+		if err := joinErrors(errs); err != nil {
+			out[len(out)-1] = reflect.ValueOf(&err).Elem()
 		}
+		ret = out
 	}
+	return ret
 }
 
 func Event[T any]() *EventType[T] {
@@ -60,35 +266,11 @@ func Event[T any]() *EventType[T] {
 	if cbType.Kind() != reflect.Func {
 		panic("Event type parameter must be a function")
 	}
-	e := &EventType[T]{}
-	fn := reflect.MakeFunc(cbType, func(args []reflect.Value) []reflect.Value {
-		var ret []reflect.Value // 返回最后一个回调的返回值
-		e.mu.RLock()
-		defer e.mu.RUnlock()
-		for _, cb := range e.cbs {
-			func() {
-				defer func() {
-					if r := recover(); r != nil {
-						errorHandler(r)
-					}
-				}()
-				ret = cb.Call(args)
-			}()
-		}
-		return ret
-	})
-	e.Call = fn.Interface().(T)
+	e := &EventType[T]{name: callerName(1, cbType), zero: zeroResults(cbType)}
+	e.Call = reflect.MakeFunc(cbType, e.dispatch).Interface().(T)
+	e.Post = reflect.MakeFunc(cbType, func(args []reflect.Value) []reflect.Value {
+		e.q.push(args)
+		return e.zero
+	}).Interface().(T)
 	return e
 }
-
-func SetErrorMode(mode ErrorMode) {
-	errorMode = mode
-}
-
-func SetErrorHandler(handler func(err any)) {
-	if handler == nil {
-		errorHandler = defaultErrorHandler
-		return
-	}
-	errorHandler = handler
-}
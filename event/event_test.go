@@ -1,8 +1,14 @@
 package event_test
 
 import (
+	"bytes"
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/deminzhang/go-common/event"
 )
@@ -60,3 +66,511 @@ func TestEvent2(t *testing.T) {
 	Event5.UnReg(e5)
 	Event5.Call("_EventTest2B", 200)
 }
+
+func TestEventPriority(t *testing.T) {
+	e := event.Event[func(order *[]string)]()
+	e.Reg(func(order *[]string) { *order = append(*order, "normal1") })
+	e.RegPriority(func(order *[]string) { *order = append(*order, "low") }, event.PriorityLow)
+	e.RegPriority(func(order *[]string) { *order = append(*order, "high") }, event.PriorityHigh)
+	e.Reg(func(order *[]string) { *order = append(*order, "normal2") })
+
+	var order []string
+	e.Call(&order)
+	want := []string{"high", "normal1", "normal2", "low"}
+	if fmt.Sprint(order) != fmt.Sprint(want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+}
+
+func TestEventStop(t *testing.T) {
+	type Damage struct{ Value int }
+	e := event.Event[func(d *Damage) error]()
+	e.Reg(func(d *Damage) error {
+		d.Value -= 10
+		return nil
+	})
+	e.RegPriority(func(d *Damage) error { //护盾吸收全部伤害
+		d.Value = 0
+		return event.ErrStop
+	}, event.PriorityHigh)
+
+	d := &Damage{Value: 100}
+	if err := e.Call(d); err != event.ErrStop {
+		t.Fatalf("err = %v, want ErrStop", err)
+	}
+	if d.Value != 0 {
+		t.Fatalf("damage = %d, want 0", d.Value)
+	}
+}
+
+func TestEventSubscription(t *testing.T) {
+	e := event.Event[func(n *int)]()
+	newAdder := func(delta int) func(n *int) {
+		return func(n *int) { *n += delta }
+	}
+	// 同一函数字面量生成的闭包,UnReg无法区分
+	sub1 := e.Reg(newAdder(1))
+	sub10 := e.Reg(newAdder(10))
+	e.RegOnce(newAdder(100))
+
+	n := 0
+	e.Call(&n)
+	if n != 111 {
+		t.Fatalf("n = %d, want 111", n)
+	}
+	sub10.Cancel()
+	sub10.Cancel()
+	n = 0
+	e.Call(&n)
+	if n != 1 {
+		t.Fatalf("n = %d, want 1", n)
+	}
+	sub1.Cancel()
+	n = 0
+	e.Call(&n)
+	if n != 0 {
+		t.Fatalf("n = %d, want 0", n)
+	}
+}
+
+func TestEventQueue(t *testing.T) {
+	e := event.Event[func(n int)]()
+	var got []int
+	e.Reg(func(n int) {
+		got = append(got, n)
+		if n == 1 {
+			e.Reg(func(n int) { got = append(got, n*10) }) //派发中注册不死锁
+			e.Post(3)                                      //留到下次Flush
+		}
+	})
+	e.SetQueue(2, event.QueueDrop)
+	e.Post(1)
+	e.Post(2)
+	e.Post(99) //丢弃
+	if e.Pending() != 2 || e.Dropped() != 1 {
+		t.Fatalf("pending = %d dropped = %d", e.Pending(), e.Dropped())
+	}
+	if n := e.Flush(); n != 2 {
+		t.Fatalf("flush = %d, want 2", n)
+	}
+	if fmt.Sprint(got) != "[1 2 20]" {
+		t.Fatalf("got = %v", got)
+	}
+	e.Flush()
+	if fmt.Sprint(got) != "[1 2 20 3 30]" {
+		t.Fatalf("got = %v", got)
+	}
+}
+
+func TestEventWorker(t *testing.T) {
+	e := event.Event[func(n int)]()
+	sum := 0
+	e.Reg(func(n int) { sum += n })
+	e.SetQueue(4, event.QueueBlock)
+	stop := e.StartWorker()
+	for i := 1; i <= 100; i++ {
+		e.Post(i)
+	}
+	stop()
+	if sum != 5050 {
+		t.Fatalf("sum = %d, want 5050", sum)
+	}
+}
+
+func TestTypedEvent(t *testing.T) {
+	var e event.Event2[string, *int]
+	e.Reg(func(s string, n *int) { *n += len(s) })
+	e.RegPriority(func(s string, n *int) { *n *= 10 }, event.PriorityHigh)
+	once := 0
+	e.RegOnce(func(s string, n *int) { once++ })
+	n := 1
+	e.Call("abc", &n)
+	e.Call("abc", &n)
+	if n != 133 || once != 1 {
+		t.Fatalf("n = %d once = %d, want 133 1", n, once)
+	}
+
+	var q event.Event1R[int, error]
+	q.Reg(func(int) error { return nil })
+	q.RegPriority(func(v int) error {
+		if v < 0 {
+			return event.ErrStop
+		}
+		return nil
+	}, event.PriorityHigh)
+	called := false
+	q.RegPriority(func(int) error { called = true; return nil }, event.PriorityLow)
+	if err := q.Call(-1); err != event.ErrStop || called {
+		t.Fatalf("err = %v called = %v", err, called)
+	}
+}
+
+func BenchmarkEventType(b *testing.B) {
+	e := event.Event[func(a, b int)]()
+	sum := 0
+	for range 4 {
+		e.Reg(func(a, b int) { sum += a + b })
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		e.Call(i, 1)
+	}
+}
+
+func BenchmarkEvent2(b *testing.B) {
+	var e event.Event2[int, int]
+	sum := 0
+	for range 4 {
+		e.Reg(func(a, b int) { sum += a + b })
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		e.Call(i, 1)
+	}
+}
+
+func TestEventCombiner(t *testing.T) {
+	canAttack := event.Event[func(id int) bool]()
+	canAttack.SetCombiner(event.CombineAnd)
+	if !canAttack.Call(1) {
+		t.Fatalf("no veto should allow")
+	}
+	canAttack.Reg(func(id int) bool { return true })
+	canAttack.Reg(func(id int) bool { return id != 2 })
+	if !canAttack.Call(1) || canAttack.Call(2) {
+		t.Fatalf("veto failed")
+	}
+
+	type Stat int
+	modifier := event.Event[func() Stat]()
+	modifier.SetCombiner(event.CombineSum)
+	modifier.Reg(func() Stat { return 5 })
+	modifier.Reg(func() Stat { panic("bad modifier") })
+	modifier.Reg(func() Stat { return -2 })
+	event.SetErrorMode(event.LogErrors)
+	defer event.SetErrorMode(event.PanicOnError)
+	if v := modifier.Call(); v != 3 {
+		t.Fatalf("sum = %d, want 3", v)
+	}
+
+	loot := event.Event[func() []string]()
+	loot.SetCombiner(event.CombineAll)
+	loot.Reg(func() []string { return []string{"a"} })
+	loot.Reg(func() []string { return nil })
+	loot.Reg(func() []string { return []string{"b", "c"} })
+	if v := loot.Call(); fmt.Sprint(v) != "[a b c]" {
+		t.Fatalf("all = %v", v)
+	}
+
+	name := event.Event[func() string]()
+	name.SetCombiner(event.CombineFirstNonZero)
+	name.Reg(func() string { return "" })
+	name.Reg(func() string { return "x" })
+	name.Reg(func() string { t.Fatalf("short circuit failed"); return "y" })
+	if v := name.Call(); v != "x" {
+		t.Fatalf("first = %q", v)
+	}
+
+	allPanic := event.Event[func() int]()
+	allPanic.Reg(func() int { panic("x") })
+	if v := allPanic.Call(); v != 0 {
+		t.Fatalf("zero = %d", v)
+	}
+}
+
+func TestEventCombinerType(t *testing.T) {
+	mustPanic := func(name string, f func()) {
+		defer func() {
+			if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), name) {
+				t.Fatalf("%s: recover = %v", name, r)
+			}
+		}()
+		f()
+	}
+	mustPanic("CombineSum", func() { event.Event[func() string]().SetCombiner(event.CombineSum) })
+	mustPanic("CombineSum", func() { event.Def[func() []int]().SetCombiner(event.CombineSum) })
+	mustPanic("CombineAll", func() { event.Event[func() int]().SetCombiner(event.CombineAll) })
+	mustPanic("CombineAll", func() { event.Def[func() map[string]int]().SetCombiner(event.CombineAll) })
+	mustPanic("CombineAnd", func() { event.Event[func() int]().SetCombiner(event.CombineAnd) })
+	event.Event[func() uint8]().SetCombiner(event.CombineSum)
+	event.Event[func() (float32, error)]().SetCombiner(event.CombineSum)
+	event.Event[func() ([]int, error)]().SetCombiner(event.CombineAll)
+}
+
+func TestEventErrors(t *testing.T) {
+	e := event.Event[func(n int) error]().SetName("test.errors")
+	var panics []*event.PanicError
+	e.SetErrorHandler(func(err any) { panics = append(panics, err.(*event.PanicError)) })
+	errA := errors.New("a")
+	errB := errors.New("b")
+	e.Reg(func(n int) error { return errA })
+	e.Reg(func(n int) error { return nil })
+	e.Reg(func(n int) error { panic("boom") })
+	e.Reg(func(n int) error { return errB })
+
+	err := e.Call(1)
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Fatalf("err = %v, want a and b joined", err)
+	}
+	var pe *event.PanicError
+	if !errors.As(err, &pe) || pe.Value != "boom" || pe.Event != "test.errors" {
+		t.Fatalf("panic not collected: %v", err)
+	}
+	if len(panics) != 1 || !strings.Contains(panics[0].Callback, "TestEventErrors") || len(panics[0].Stack) == 0 {
+		t.Fatalf("panics = %v", panics)
+	}
+
+	one := event.Event[func() error]()
+	one.Reg(func() error { return nil })
+	one.Reg(func() error { return errA })
+	if err := one.Call(); err != errA {
+		t.Fatalf("single err = %v, want a", err)
+	}
+}
+
+func TestStaticEventErrorMode(t *testing.T) {
+	e := event.Def[func()]()
+	e.Reg(func() { panic("boom") })
+	e.SetErrorMode(event.LogErrors)
+	e.Call() //不panic
+
+	e.SetErrorMode(event.PanicOnError)
+	defer func() {
+		r := recover()
+		pe, ok := r.(*event.PanicError)
+		if !ok || pe.Value != "boom" || !strings.Contains(pe.Event, "event_test.go") {
+			t.Fatalf("recover = %v", r)
+		}
+	}()
+	e.Call()
+}
+
+func TestBus(t *testing.T) {
+	bus := event.NewBus()
+	bus.SetPublishStats(100)
+	var got []string
+	event.Subscribe(bus, "player.*.died", func(topic string, id int) {
+		got = append(got, fmt.Sprint("died ", topic, " ", id))
+	})
+	all := event.Subscribe(bus, "player.**", func(topic string, p any) {
+		got = append(got, "all "+topic)
+	})
+	event.Subscribe(bus, "player.1.died", func(topic string, s string) {
+		t.Fatalf("payload type mismatch should skip")
+	})
+
+	if n := bus.Publish("player.1.died", 1); n != 2 {
+		t.Fatalf("called = %d, want 2", n)
+	}
+	bus.Publish("player.login", 2)
+	bus.Publish("npc.1.died", 3)
+	want := "[died player.1.died 1 all player.1.died all player.login]"
+	if fmt.Sprint(got) != want {
+		t.Fatalf("got = %v", got)
+	}
+
+	if n := bus.SubscriberCount("player.2.died"); n != 2 {
+		t.Fatalf("subscribers = %d, want 2", n)
+	}
+	all.Cancel()
+	if n := bus.SubscriberCount("player.2.died"); n != 1 {
+		t.Fatalf("subscribers = %d, want 1", n)
+	}
+	topics := bus.Topics()
+	if len(topics) != 3 || topics[0].Topic != "npc.1.died" || topics[0].Subscribers != 0 ||
+		topics[1].Topic != "player.1.died" || topics[1].Subscribers != 2 || topics[1].Published != 1 {
+		t.Fatalf("topics = %+v", topics)
+	}
+}
+
+func TestBusPublishStats(t *testing.T) {
+	bus := event.NewBus()
+	bus.Publish("player.1.died", nil)
+	if topics := bus.Topics(); len(topics) != 0 {
+		t.Fatalf("stats off, topics = %+v", topics)
+	}
+
+	bus.SetPublishStats(2)
+	for _, topic := range []string{"player.1.died", "player.2.died", "player.3.died", "player.1.died"} {
+		bus.Publish(topic, nil)
+	}
+	topics := bus.Topics()
+	if len(topics) != 2 || topics[0].Topic != "player.1.died" || topics[0].Published != 2 ||
+		topics[1].Topic != "player.2.died" || topics[1].Published != 1 {
+		t.Fatalf("topics = %+v", topics)
+	}
+
+	bus.SetPublishStats(0)
+	if topics := bus.Topics(); len(topics) != 0 {
+		t.Fatalf("stats cleared, topics = %+v", topics)
+	}
+}
+
+func TestStaticEventSeal(t *testing.T) {
+	e := event.Def[func()]().SetName("test.seal")
+	e.Reg(func() {})
+	e.Seal()
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatalf("expected panic after Seal")
+			}
+		}()
+		e.Reg(func() {})
+	}()
+
+	var info *event.StaticEventInfo
+	for _, i := range event.StaticEvents() {
+		if i.Name == "test.seal" {
+			info = &i
+		}
+	}
+	if info == nil || !info.Sealed || len(info.Callbacks) != 1 {
+		t.Fatalf("info = %+v", info)
+	}
+
+	var sb strings.Builder
+	event.DumpStatic(&sb)
+	if !strings.Contains(sb.String(), "test.seal (1 callbacks sealed)") ||
+		!strings.Contains(sb.String(), "event_test.go") {
+		t.Fatalf("dump = %s", sb.String())
+	}
+}
+
+func TestEventTracer(t *testing.T) {
+	rec := event.NewRecorder()
+	e := event.Event[func(n int)]().SetName("test.trace")
+	e.SetTracer(rec)
+	e.SetErrorMode(event.LogErrors)
+	e.Reg(func(n int) { time.Sleep(time.Millisecond) })
+	e.Reg(func(n int) {
+		if n == 1 {
+			panic("boom")
+		}
+	})
+	e.Call(0)
+	e.Call(1)
+
+	stats := rec.Stats()
+	if len(stats) != 1 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	s := stats[0]
+	if s.Name != "test.trace" || s.Dispatch.Count != 2 || s.Subscribers != 2 || s.Panics != 1 || len(s.Callbacks) != 2 {
+		t.Fatalf("stats = %+v", s)
+	}
+	if s.Dispatch.Total < 2*time.Millisecond || s.Dispatch.Buckets[0]+s.Dispatch.Buckets[3] != 0 {
+		t.Fatalf("histogram = %+v", s.Dispatch)
+	}
+
+	var sb strings.Builder
+	rec.Dump(&sb)
+	if !strings.Contains(sb.String(), "test.trace: calls=2 subscribers=2 panics=1") {
+		t.Fatalf("dump = %s", sb.String())
+	}
+}
+
+type testVec struct{ X, Y int32 }
+
+type testVecCodec struct{}
+
+func (testVecCodec) Encode(w io.Writer, v testVec) error {
+	return binary.Write(w, binary.LittleEndian, v)
+}
+
+func (testVecCodec) Decode(r io.Reader) (v testVec, err error) {
+	err = binary.Read(r, binary.LittleEndian, &v)
+	return
+}
+
+// replayCalls 回放日志到一组新的回调,返回回调调用序列
+func replayCalls(t *testing.T, log []byte) []string {
+	var calls []string
+	move := event.Event[func(id int, to testVec)]().SetName("test.move")
+	move.Reg(func(id int, to testVec) { calls = append(calls, fmt.Sprint("move ", id, to)) })
+	say := event.Event[func(id int, text string, loud bool)]().SetName("test.say")
+	say.Reg(func(id int, text string, loud bool) { calls = append(calls, fmt.Sprint("say ", id, text, loud)) })
+
+	p := event.NewReplayer(bytes.NewReader(log))
+	event.Replay(p, move)
+	event.Replay(p, say)
+	for {
+		err := p.Step()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("replay: %v", err)
+		}
+		calls = append(calls, fmt.Sprint("tick ", p.Tick()))
+	}
+	return calls
+}
+
+func TestEventReplay(t *testing.T) {
+	event.RegisterCodec[testVec](testVecCodec{})
+	move := event.Event[func(id int, to testVec)]().SetName("test.move")
+	say := event.Event[func(id int, text string, loud bool)]().SetName("test.say")
+	skip := event.Event[func(f float64)]().SetName("test.unbound")
+
+	var buf bytes.Buffer
+	j := event.NewJournal(&buf)
+	event.Record(j, move)
+	event.Record(j, say)
+	event.Record(j, skip)
+	for tick := uint64(1); tick <= 3; tick++ {
+		j.SetTick(tick)
+		move.Call(int(tick), testVec{X: int32(tick), Y: -int32(tick)})
+		skip.Call(1.5)
+		say.Call(-1, "hi", tick%2 == 0)
+	}
+	if err := j.Flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	first := replayCalls(t, buf.Bytes())
+	second := replayCalls(t, buf.Bytes())
+	if len(first) != 15 || fmt.Sprint(first) != fmt.Sprint(second) {
+		t.Fatalf("replays differ:\n%v\n%v", first, second)
+	}
+	if first[0] != "move 1 {1 -1}" || first[3] != "say -1hifalse" || first[len(first)-1] != "tick 3" {
+		t.Fatalf("calls = %v", first)
+	}
+}
+
+func TestReplayCorrupt(t *testing.T) {
+	journal := func(recs ...[]byte) []byte {
+		return append([]byte("EVJ1"), bytes.Join(recs, nil)...)
+	}
+	define := append([]byte{0, 0, 8}, "test.say"...)
+	// 记录长度远超实际,不能按它分配内存
+	hugeBlock := binary.AppendUvarint([]byte{0, 1}, 1<<40)
+	// 参数中字符串长度超出记录
+	args := binary.AppendUvarint(binary.AppendVarint(nil, 1), 1<<40)
+	hugeString := append(binary.AppendUvarint([]byte{1, 0, 5}, uint64(len(args))), args...)
+	for _, c := range []struct {
+		log  []byte
+		want string
+	}{
+		{journal(hugeBlock), "exceeds"},
+		{journal(define, hugeString), "unexpected EOF"},
+		{journal(define, []byte{1, 0, 5, 10, 1}), "unexpected EOF"},
+	} {
+		say := event.Event[func(id int, text string, loud bool)]().SetName("test.say")
+		say.Reg(func(id int, text string, loud bool) { t.Fatalf("corrupt record dispatched") })
+		p := event.NewReplayer(bytes.NewReader(c.log))
+		event.Replay(p, say)
+		if err := p.Run(); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("Run(%x) = %v, want %q", c.log, err, c.want)
+		}
+	}
+
+	// 录制时就拒绝过大的记录
+	say := event.Event[func(id int, text string, loud bool)]().SetName("test.say")
+	j := event.NewJournal(io.Discard)
+	event.Record(j, say)
+	say.Call(1, strings.Repeat("x", 16<<20), false)
+	if err := j.Flush(); err == nil || !strings.Contains(err.Error(), "exceed") {
+		t.Errorf("Flush = %v, want size error", err)
+	}
+}
@@ -0,0 +1,140 @@
+package event
+
+import (
+	"reflect"
+	"sync"
+)
+
+// QueuePolicy 队列满时Post的处理方式
+type QueuePolicy int
+
+const (
+	QueueGrow  QueuePolicy = iota //忽略容量继续追加(默认)
+	QueueDrop                     //丢弃新事件,计入Dropped
+	QueueBlock                    //阻塞直到Flush或worker腾出空间
+)
+
+type queue struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	items    [][]reflect.Value
+	capacity int
+	policy   QueuePolicy
+	dropped  uint64
+	closed   bool
+}
+
+func (q *queue) init() {
+	if q.cond == nil {
+		q.cond = sync.NewCond(&q.mu)
+	}
+}
+
+func (q *queue) push(args []reflect.Value) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.init()
+	for q.capacity > 0 && len(q.items) >= q.capacity {
+		switch q.policy {
+		case QueueDrop:
+			q.dropped++
+			return
+		case QueueBlock:
+			q.cond.Wait()
+			continue
+		}
+		break
+	}
+	q.items = append(q.items, args)
+	q.cond.Broadcast()
+}
+
+// take 取走当前所有排队事件,wait为true时队列空则阻塞直到有事件或关闭
+func (q *queue) take(wait bool) [][]reflect.Value {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.init()
+	for wait && len(q.items) == 0 && !q.closed {
+		q.cond.Wait()
+	}
+	items := q.items
+	q.items = nil
+	q.cond.Broadcast() //唤醒QueueBlock阻塞的Post
+	return items
+}
+
+// SetQueue 设置Post队列容量和满时策略,capacity<=0为不限容量
+func (e *EventType[T]) SetQueue(capacity int, policy QueuePolicy) {
+	e.q.mu.Lock()
+	defer e.q.mu.Unlock()
+	e.q.init()
+	e.q.capacity = capacity
+	e.q.policy = policy
+	e.q.cond.Broadcast()
+}
+
+// Pending 排队中未派发的事件数
+func (e *EventType[T]) Pending() int {
+	e.q.mu.Lock()
+	defer e.q.mu.Unlock()
+	return len(e.q.items)
+}
+
+// Dropped QueueDrop策略下累计丢弃的事件数
+func (e *EventType[T]) Dropped() uint64 {
+	e.q.mu.Lock()
+	defer e.q.mu.Unlock()
+	return e.q.dropped
+}
+
+// Flush 派发当前排队的所有事件,返回派发数量,每帧调用一次
+// 派发过程中新Post的事件留到下次Flush
+// QueueBlock策略下不要在Flush所在的goroutine里Post,满了会死锁
+func (e *EventType[T]) Flush() int {
+	items := e.q.take(false)
+	for _, args := range items {
+		e.dispatch(args)
+	}
+	return len(items)
+}
+
+// StartWorker 启动goroutine持续派发排队事件,返回的stop会派发完剩余事件后退出
+func (e *EventType[T]) StartWorker() (stop func()) {
+	e.q.mu.Lock()
+	e.q.init()
+	e.q.closed = false
+	e.q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			items := e.q.take(true)
+			for _, args := range items {
+				e.dispatch(args)
+			}
+			if len(items) == 0 {
+				return //closed且已取空
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			e.q.mu.Lock()
+			e.q.closed = true
+			e.q.cond.Broadcast()
+			e.q.mu.Unlock()
+			<-done
+		})
+	}
+}
+
+// zeroResults 函数类型的零值返回
+func zeroResults(fnType reflect.Type) []reflect.Value {
+	ret := make([]reflect.Value, fnType.NumOut())
+	for i := range ret {
+		ret[i] = reflect.Zero(fnType.Out(i))
+	}
+	return ret
+}
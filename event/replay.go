@@ -0,0 +1,380 @@
+package event
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sync"
+)
+
+// 事件录制与回放,用于复现不同步
+// 录制:
+//
+//	j := event.NewJournal(f)
+//	event.Record(j, OnMove)
+//	j.SetTick(tick) //每帧
+//	j.Flush()
+//
+// 回放到一组新的回调:
+//
+//	p := event.NewReplayer(f)
+//	event.Replay(p, freshOnMove) //按事件名绑定,录制和回放的事件名需一致
+//	err := p.Run()
+//
+// 日志格式: 文件头journalMagic,之后是记录序列
+// 定义记录: 0, uvarint(id), uvarint(len), name
+// 调用记录: 1, uvarint(id), uvarint(tick), uvarint(len), args
+
+const journalMagic = "EVJ1"
+
+// maxBlockSize 单条记录的事件名或参数的最大字节数,回放时超过视为日志损坏,避免按错误的长度分配内存
+const maxBlockSize = 16 << 20
+
+const (
+	recordDefine byte = iota
+	recordCall
+)
+
+// Codec 参数类型的编解码,未注册的bool/整数/浮点数/string类型按Kind内置编码
+type Codec[T any] interface {
+	Encode(w io.Writer, v T) error
+	Decode(r io.Reader) (T, error)
+}
+
+type valueCodec struct {
+	encode func(w io.Writer, v reflect.Value) error
+	decode func(r io.Reader) (reflect.Value, error)
+}
+
+var (
+	codecMu sync.RWMutex
+	codecs  = map[reflect.Type]valueCodec{}
+)
+
+// RegisterCodec 注册参数类型T的编解码
+func RegisterCodec[T any](c Codec[T]) {
+	codecMu.Lock()
+	defer codecMu.Unlock()
+	codecs[reflect.TypeFor[T]()] = valueCodec{
+		encode: func(w io.Writer, v reflect.Value) error { return c.Encode(w, v.Interface().(T)) },
+		decode: func(r io.Reader) (reflect.Value, error) {
+			v, err := c.Decode(r)
+			return reflect.ValueOf(&v).Elem(), err
+		},
+	}
+}
+
+func encodeValue(w *bytes.Buffer, v reflect.Value) error {
+	codecMu.RLock()
+	c, ok := codecs[v.Type()]
+	codecMu.RUnlock()
+	if ok {
+		return c.encode(w, v)
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			w.WriteByte(1)
+		} else {
+			w.WriteByte(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		w.Write(binary.AppendVarint(nil, v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		w.Write(binary.AppendUvarint(nil, v.Uint()))
+	case reflect.Float32, reflect.Float64:
+		w.Write(binary.LittleEndian.AppendUint64(nil, math.Float64bits(v.Float())))
+	case reflect.String:
+		w.Write(binary.AppendUvarint(nil, uint64(v.Len())))
+		w.WriteString(v.String())
+	default:
+		return fmt.Errorf("event: no codec for %s", v.Type())
+	}
+	return nil
+}
+
+func decodeValue(r *bytes.Reader, t reflect.Type) (reflect.Value, error) {
+	codecMu.RLock()
+	c, ok := codecs[t]
+	codecMu.RUnlock()
+	if ok {
+		return c.decode(r)
+	}
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Bool:
+		b, err := r.ReadByte()
+		if err != nil {
+			return v, err
+		}
+		v.SetBool(b != 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := binary.ReadVarint(r)
+		if err != nil {
+			return v, err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return v, err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		var b [8]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return v, err
+		}
+		v.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(b[:])))
+	case reflect.String:
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return v, err
+		}
+		if n > uint64(r.Len()) { //长度超出记录,不按它分配
+			return v, io.ErrUnexpectedEOF
+		}
+		b := make([]byte, n)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return v, err
+		}
+		v.SetString(string(b))
+	default:
+		return v, fmt.Errorf("event: no codec for %s", t)
+	}
+	return v, nil
+}
+
+// Journal 事件录制,写入紧凑的二进制日志
+type Journal struct {
+	mu   sync.Mutex
+	w    *bufio.Writer
+	ids  map[string]uint64
+	tick uint64
+	buf  bytes.Buffer
+	err  error
+}
+
+func NewJournal(w io.Writer) *Journal {
+	j := &Journal{w: bufio.NewWriter(w), ids: make(map[string]uint64)}
+	_, j.err = j.w.WriteString(journalMagic)
+	return j
+}
+
+// SetTick 设置当前帧号,之后的调用记录在该帧
+func (j *Journal) SetTick(tick uint64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.tick = tick
+}
+
+// Flush 写出缓冲,返回录制过程中的第一个错误
+func (j *Journal) Flush() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.err != nil {
+		return j.err
+	}
+	j.err = j.w.Flush()
+	return j.err
+}
+
+// Err 录制过程中的第一个错误
+func (j *Journal) Err() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.err
+}
+
+func (j *Journal) record(name string, args []reflect.Value) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.err != nil {
+		return
+	}
+	id, ok := j.ids[name]
+	if !ok {
+		id = uint64(len(j.ids))
+		j.ids[name] = id
+		j.writeRecord(recordDefine, id, []byte(name))
+	}
+	j.buf.Reset()
+	for _, arg := range args {
+		if err := encodeValue(&j.buf, arg); err != nil {
+			j.err = fmt.Errorf("event %s: %w", name, err)
+			return
+		}
+	}
+	if j.buf.Len() > maxBlockSize {
+		j.err = fmt.Errorf("event %s: args of %d bytes exceed %d", name, j.buf.Len(), maxBlockSize)
+		return
+	}
+	rec := []byte{recordCall}
+	rec = binary.AppendUvarint(rec, id)
+	rec = binary.AppendUvarint(rec, j.tick)
+	rec = binary.AppendUvarint(rec, uint64(j.buf.Len()))
+	j.write(append(rec, j.buf.Bytes()...))
+}
+
+func (j *Journal) writeRecord(kind byte, id uint64, data []byte) {
+	rec := []byte{kind}
+	rec = binary.AppendUvarint(rec, id)
+	rec = binary.AppendUvarint(rec, uint64(len(data)))
+	j.write(append(rec, data...))
+}
+
+func (j *Journal) write(b []byte) {
+	if j.err == nil {
+		_, j.err = j.w.Write(b)
+	}
+}
+
+// Record 录制事件e的每次派发(Call及Flush),e的名字用于回放时绑定
+func Record[T any](j *Journal, e *EventType[T]) {
+	hook := func(args []reflect.Value) { j.record(e.name, args) }
+	e.record.Store(&hook)
+}
+
+// StopRecord 停止录制事件e
+func StopRecord[T any](e *EventType[T]) {
+	e.record.Store(nil)
+}
+
+// Replayer 读取Journal日志并派发到绑定的事件
+type Replayer struct {
+	r        *bufio.Reader
+	names    map[uint64]string
+	bindings map[string]replayBinding
+	tick     uint64
+	err      error
+}
+
+type replayBinding struct {
+	types    []reflect.Type
+	dispatch func(args []reflect.Value) []reflect.Value
+}
+
+func NewReplayer(r io.Reader) *Replayer {
+	p := &Replayer{r: bufio.NewReader(r), names: map[uint64]string{}, bindings: map[string]replayBinding{}}
+	magic := make([]byte, len(journalMagic))
+	if _, err := io.ReadFull(p.r, magic); err != nil {
+		p.err = err
+	} else if string(magic) != journalMagic {
+		p.err = errors.New("event: not a journal")
+	}
+	return p
+}
+
+// Replay 按e的名字绑定,日志中该事件的调用派发到e的回调,未绑定的事件跳过
+func Replay[T any](p *Replayer, e *EventType[T]) {
+	cbType := reflect.TypeFor[T]()
+	types := make([]reflect.Type, cbType.NumIn())
+	for i := range types {
+		types[i] = cbType.In(i)
+	}
+	p.bindings[e.name] = replayBinding{types: types, dispatch: e.dispatch}
+}
+
+// Tick 最近一条调用记录的帧号
+func (p *Replayer) Tick() uint64 {
+	return p.tick
+}
+
+// Step 回放下一条调用记录,日志结束返回io.EOF
+func (p *Replayer) Step() error {
+	for p.err == nil {
+		kind, err := p.r.ReadByte()
+		if err != nil {
+			p.err = err
+			break
+		}
+		id, err := binary.ReadUvarint(p.r)
+		if err != nil {
+			p.err = unexpectedEOF(err)
+			break
+		}
+		switch kind {
+		case recordDefine:
+			data, err := p.readBlock()
+			if err != nil {
+				p.err = err
+				break
+			}
+			p.names[id] = string(data)
+		case recordCall:
+			tick, err := binary.ReadUvarint(p.r)
+			if err != nil {
+				p.err = unexpectedEOF(err)
+				break
+			}
+			data, err := p.readBlock()
+			if err != nil {
+				p.err = err
+				break
+			}
+			p.tick = tick
+			return p.call(p.names[id], data)
+		default:
+			p.err = fmt.Errorf("event: bad journal record %d", kind)
+		}
+	}
+	return p.err
+}
+
+func (p *Replayer) call(name string, data []byte) error {
+	b, ok := p.bindings[name]
+	if !ok {
+		return nil
+	}
+	r := bytes.NewReader(data)
+	args := make([]reflect.Value, len(b.types))
+	for i, t := range b.types {
+		v, err := decodeValue(r, t)
+		if err != nil {
+			p.err = fmt.Errorf("event %s: %w", name, err)
+			return p.err
+		}
+		args[i] = v
+	}
+	b.dispatch(args)
+	return nil
+}
+
+// Run 回放全部记录
+func (p *Replayer) Run() error {
+	for {
+		if err := p.Step(); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func (p *Replayer) readBlock() ([]byte, error) {
+	n, err := binary.ReadUvarint(p.r)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if n > maxBlockSize {
+		return nil, fmt.Errorf("event: journal record of %d bytes exceeds %d", n, maxBlockSize)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(p.r, data); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return data, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -2,22 +2,115 @@ package event
 
 import (
 	"fmt"
+	"io"
 	"reflect"
+	"sync"
+	"sync/atomic"
 )
 
 // 静态事件 禁止后续注册
 type StaticEvent[T any] struct {
-	cbs  []reflect.Value
-	Call T
+	errorPolicy
+	tracing
+	name   string
+	cbs    []*handler[reflect.Value]
+	zero   []reflect.Value
+	comb   Combiner
+	sealed atomic.Bool
+	Call   T
 }
 
-var lockReg bool //禁止后续注册,静态事件应在init期间注册好
+// StaticEventInfo 静态事件的注册信息
+type StaticEventInfo struct {
+	Name      string
+	Callbacks []string //回调函数名及定义位置,按执行顺序
+	Sealed    bool
+}
+
+// staticEvent 注册表里的静态事件
+type staticEvent interface {
+	Info() StaticEventInfo
+	Seal()
+}
+
+var (
+	lockReg atomic.Bool //禁止后续注册,静态事件应在init期间注册好
+
+	staticMu     sync.Mutex
+	staticEvents []staticEvent //所有Def定义的静态事件,按定义顺序
+)
+
+// SealAll 锁定所有静态事件的注册,之后Reg会panic,应在init完成后调用
+func SealAll() {
+	lockReg.Store(true)
+}
+
+// StaticEvents 所有静态事件的注册信息,按定义顺序
+func StaticEvents() []StaticEventInfo {
+	staticMu.Lock()
+	defer staticMu.Unlock()
+	infos := make([]StaticEventInfo, len(staticEvents))
+	for i, e := range staticEvents {
+		infos[i] = e.Info()
+	}
+	return infos
+}
+
+// DumpStatic 输出所有静态事件及其回调,启动时用于核对各模块的注册
+func DumpStatic(w io.Writer) {
+	for _, info := range StaticEvents() {
+		sealed := ""
+		if info.Sealed {
+			sealed = " sealed"
+		}
+		fmt.Fprintf(w, "%s (%d callbacks%s)\n", info.Name, len(info.Callbacks), sealed)
+		for i, cb := range info.Callbacks {
+			fmt.Fprintf(w, "\t%d. %s\n", i+1, cb)
+		}
+	}
+}
 
 func (e *StaticEvent[T]) Reg(cb T) {
-	if lockReg {
-		panic("static event had lock reg. must reg in init")
+	e.RegPriority(cb, PriorityNormal)
+}
+
+// RegPriority 按优先级注册,数值大的先执行
+func (e *StaticEvent[T]) RegPriority(cb T, priority int) {
+	if e.sealed.Load() || lockReg.Load() {
+		panic(fmt.Sprintf("static event %s had lock reg. must reg in init", e.name))
+	}
+	e.cbs = insertHandler(e.cbs, newHandler(reflect.ValueOf(cb), priority))
+}
+
+// Seal 锁定本事件的注册,之后Reg会panic
+func (e *StaticEvent[T]) Seal() {
+	e.sealed.Store(true)
+}
+
+// Info 注册信息
+func (e *StaticEvent[T]) Info() StaticEventInfo {
+	info := StaticEventInfo{Name: e.name, Sealed: e.sealed.Load() || lockReg.Load()}
+	for _, cb := range e.cbs {
+		info.Callbacks = append(info.Callbacks, cb.name())
 	}
-	e.cbs = append(e.cbs, reflect.ValueOf(cb))
+	return info
+}
+
+// Name 事件名,默认为类型和定义位置
+func (e *StaticEvent[T]) Name() string {
+	return e.name
+}
+
+// SetName 设置事件名,用于错误信息和调试,应在init期间设置
+func (e *StaticEvent[T]) SetName(name string) *StaticEvent[T] {
+	e.name = name
+	return e
+}
+
+// SetCombiner 设置返回值合并方式,nil恢复为CombineLast,应在init期间设置
+func (e *StaticEvent[T]) SetCombiner(c Combiner) {
+	checkCombiner(e.zero, c)
+	e.comb = c
 }
 
 func Def[T any]() *StaticEvent[T] {
@@ -25,28 +118,18 @@ func Def[T any]() *StaticEvent[T] {
 	if cbType.Kind() != reflect.Func {
 		panic("Event type parameter must be a function")
 	}
-	e := &StaticEvent[T]{}
+	e := &StaticEvent[T]{name: callerName(1, cbType), zero: zeroResults(cbType)}
 	fn := reflect.MakeFunc(cbType, func(args []reflect.Value) []reflect.Value {
-		var ret []reflect.Value // 返回最后一个回调的返回值
-		for _, cb := range e.cbs {
-			func() {
-				defer func() {
-					switch errorMode {
-					case PanicOnError:
-						return
-					}
-					if r := recover(); r != nil {
-						switch errorMode {
-						case LogErrors:
-							fmt.Printf("Event callback panic: %v\n", r)
-						}
-					}
-				}()
-				ret = cb.Call(args)
-			}()
+		comb := e.comb
+		if comb == nil {
+			comb = CombineLast
 		}
-		return ret
+		return callHandlers(e.name, &e.errorPolicy, e.current(), e.cbs, comb, e.zero, args, new([]uint64))
 	})
 	e.Call = fn.Interface().(T)
+
+	staticMu.Lock()
+	staticEvents = append(staticEvents, e)
+	staticMu.Unlock()
 	return e
 }
@@ -0,0 +1,198 @@
+package event
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Tracer 事件派发追踪,用于找出慢回调和高频事件
+// 回调在派发的goroutine里同步调用,实现需自行保证并发安全
+type Tracer interface {
+	// DispatchStart 开始派发,subscribers为本次派发的回调数
+	DispatchStart(event string, subscribers int)
+	// Callback 单个回调结束,panicked为回调是否panic
+	Callback(event, callback string, d time.Duration, panicked bool)
+	// DispatchEnd 派发结束
+	DispatchEnd(event string, d time.Duration)
+}
+
+var globalTracer atomic.Pointer[Tracer]
+
+// SetTracer 设置全局追踪,未单独设置的事件使用,nil关闭
+func SetTracer(t Tracer) {
+	if t == nil {
+		globalTracer.Store(nil)
+		return
+	}
+	globalTracer.Store(&t)
+}
+
+// tracing 单个事件的追踪设置
+type tracing struct {
+	tracer atomic.Pointer[Tracer]
+}
+
+// SetTracer 设置本事件的追踪,nil恢复为使用全局设置
+func (t *tracing) SetTracer(tracer Tracer) {
+	if tracer == nil {
+		t.tracer.Store(nil)
+		return
+	}
+	t.tracer.Store(&tracer)
+}
+
+func (t *tracing) current() Tracer {
+	if p := t.tracer.Load(); p != nil {
+		return *p
+	}
+	if p := globalTracer.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// 耗时直方图的桶上界,最后一桶为>=100ms
+var histogramBounds = [...]time.Duration{
+	time.Microsecond,
+	10 * time.Microsecond,
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+}
+
+// Histogram 耗时统计
+type Histogram struct {
+	Count   uint64
+	Total   time.Duration
+	Max     time.Duration
+	Buckets [len(histogramBounds) + 1]uint64 //<1us,<10us,<100us,<1ms,<10ms,<100ms,>=100ms
+}
+
+func (h *Histogram) add(d time.Duration) {
+	h.Count++
+	h.Total += d
+	h.Max = max(h.Max, d)
+	i := sort.Search(len(histogramBounds), func(i int) bool { return d < histogramBounds[i] })
+	h.Buckets[i]++
+}
+
+// Avg 平均耗时
+func (h *Histogram) Avg() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Total / time.Duration(h.Count)
+}
+
+// EventStats 单个事件的统计
+type EventStats struct {
+	Name        string
+	Subscribers int //最近一次派发的回调数
+	Panics      uint64
+	Dispatch    Histogram
+	Callbacks   map[string]*CallbackStats
+}
+
+// CallbackStats 单个回调的统计
+type CallbackStats struct {
+	Name   string
+	Panics uint64
+	Histogram
+}
+
+// Recorder 内存中的Tracer实现,统计各事件及回调的耗时分布
+type Recorder struct {
+	mu     sync.Mutex
+	events map[string]*EventStats
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{events: make(map[string]*EventStats)}
+}
+
+func (r *Recorder) stats(event string) *EventStats {
+	s := r.events[event]
+	if s == nil {
+		s = &EventStats{Name: event, Callbacks: make(map[string]*CallbackStats)}
+		r.events[event] = s
+	}
+	return s
+}
+
+func (r *Recorder) DispatchStart(event string, subscribers int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats(event).Subscribers = subscribers
+}
+
+func (r *Recorder) Callback(event, callback string, d time.Duration, panicked bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.stats(event)
+	cs := s.Callbacks[callback]
+	if cs == nil {
+		cs = &CallbackStats{Name: callback}
+		s.Callbacks[callback] = cs
+	}
+	cs.add(d)
+	if panicked {
+		cs.Panics++
+		s.Panics++
+	}
+}
+
+func (r *Recorder) DispatchEnd(event string, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats(event).Dispatch.add(d)
+}
+
+// Reset 清空统计
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = make(map[string]*EventStats)
+}
+
+// Stats 统计快照,按派发总耗时降序
+func (r *Recorder) Stats() []EventStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := make([]EventStats, 0, len(r.events))
+	for _, s := range r.events {
+		c := *s
+		c.Callbacks = make(map[string]*CallbackStats, len(s.Callbacks))
+		for k, v := range s.Callbacks {
+			cs := *v
+			c.Callbacks[k] = &cs
+		}
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Dispatch.Total > list[j].Dispatch.Total })
+	return list
+}
+
+// Dump 输出各事件的派发直方图及回调耗时,按总耗时降序
+func (r *Recorder) Dump(w io.Writer) {
+	for _, s := range r.Stats() {
+		d := &s.Dispatch
+		fmt.Fprintf(w, "%s: calls=%d subscribers=%d panics=%d total=%v avg=%v max=%v\n",
+			s.Name, d.Count, s.Subscribers, s.Panics, d.Total, d.Avg(), d.Max)
+		fmt.Fprintf(w, "\t<1us:%d <10us:%d <100us:%d <1ms:%d <10ms:%d <100ms:%d >=100ms:%d\n",
+			d.Buckets[0], d.Buckets[1], d.Buckets[2], d.Buckets[3], d.Buckets[4], d.Buckets[5], d.Buckets[6])
+		cbs := make([]*CallbackStats, 0, len(s.Callbacks))
+		for _, cs := range s.Callbacks {
+			cbs = append(cbs, cs)
+		}
+		sort.Slice(cbs, func(i, j int) bool { return cbs[i].Total > cbs[j].Total })
+		for _, cs := range cbs {
+			fmt.Fprintf(w, "\t%s: calls=%d panics=%d total=%v avg=%v max=%v\n",
+				cs.Name, cs.Count, cs.Panics, cs.Total, cs.Avg(), cs.Max)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package event
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+)
+
+// 类型化事件,直接保存func不走反射,用于每帧调用的热路径
+// 零值可用:
+//
+//	var OnHit event.Event2[*Unit, int]
+//	OnHit.Reg(func(u *Unit, dmg int) { ... })
+//	OnHit.Call(u, 10)
+//
+// EventNR为带返回值的版本,Call返回最后一个回调的返回值
+// R为error时支持ErrStop中止派发,返回所有回调的error及panic,单个原样返回,多个errors.Join
+
+type typedEvent[F any] struct {
+	errorPolicy
+	name string
+	mu   sync.RWMutex
+	cbs  []*handler[F] //写时复制
+}
+
+func (e *typedEvent[F]) Reg(cb F) *Subscription {
+	return e.RegPriority(cb, PriorityNormal)
+}
+
+// RegPriority 按优先级注册,数值大的先执行
+func (e *typedEvent[F]) RegPriority(cb F, priority int) *Subscription {
+	return e.add(newHandler(cb, priority))
+}
+
+// RegOnce 注册只触发一次的回调,触发后自动注销
+func (e *typedEvent[F]) RegOnce(cb F) *Subscription {
+	h := newHandler(cb, PriorityNormal)
+	h.once = true
+	return e.add(h)
+}
+
+// Name 事件名,零值为空
+func (e *typedEvent[F]) Name() string {
+	return e.name
+}
+
+// SetName 设置事件名,用于错误信息和调试,应在使用前设置
+func (e *typedEvent[F]) SetName(name string) {
+	e.name = name
+}
+
+// Len 已注册的回调数
+func (e *typedEvent[F]) Len() int {
+	return len(e.snapshot())
+}
+
+func (e *typedEvent[F]) add(h *handler[F]) *Subscription {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.cbs = insertHandler(e.cbs, h)
+	return &Subscription{cancel: func() { e.remove(h.id) }}
+}
+
+func (e *typedEvent[F]) remove(id uint64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.cbs = removeHandler(e.cbs, func(h *handler[F]) bool { return h.id == id })
+}
+
+func (e *typedEvent[F]) snapshot() []*handler[F] {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.cbs
+}
+
+// each 按顺序调用回调,call返回true时中止派发,返回recover到的panic
+func (e *typedEvent[F]) each(call func(cb F) bool) (panics []error) {
+	for _, cb := range e.snapshot() {
+		if !cb.fire() {
+			continue
+		}
+		if cb.once {
+			defer e.remove(cb.id)
+		}
+		stop, err := e.invoke(cb.fn, call)
+		if err != nil {
+			panics = append(panics, err)
+		}
+		if stop {
+			return
+		}
+	}
+	return
+}
+
+func (e *typedEvent[F]) invoke(fn F, call func(cb F) bool) (stop bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = e.recoverCallback(e.name, reflect.ValueOf(fn), r)
+		}
+	}()
+	return call(fn), nil
+}
+
+// isErrorType R是否为error
+func isErrorType[R any]() bool {
+	_, ok := any((*R)(nil)).(*error)
+	return ok
+}
+
+// result R为error时收集error,返回是否为ErrStop
+func result[R any](r R, errs *[]error) (stop bool) {
+	if !isErrorType[R]() {
+		return false
+	}
+	err, _ := any(r).(error)
+	if err == nil {
+		return false
+	}
+	*errs = append(*errs, err)
+	return errors.Is(err, ErrStop)
+}
+
+// finish R为error时返回收集的error(含panic),单个原样返回,多个errors.Join
+func finish[R any](ret R, errs, panics []error) R {
+	if !isErrorType[R]() {
+		return ret
+	}
+	err := joinErrors(append(errs, panics...))
+	if err == nil {
+		var zero R
+		return zero
+	}
+	return any(err).(R)
+}
+
+type Event0 struct{ typedEvent[func()] }
+
+func (e *Event0) Call() {
+	e.each(func(cb func()) bool { cb(); return false })
+}
+
+type Event1[A any] struct{ typedEvent[func(A)] }
+
+func (e *Event1[A]) Call(a A) {
+	e.each(func(cb func(A)) bool { cb(a); return false })
+}
+
+type Event2[A, B any] struct{ typedEvent[func(A, B)] }
+
+func (e *Event2[A, B]) Call(a A, b B) {
+	e.each(func(cb func(A, B)) bool { cb(a, b); return false })
+}
+
+type Event3[A, B, C any] struct{ typedEvent[func(A, B, C)] }
+
+func (e *Event3[A, B, C]) Call(a A, b B, c C) {
+	e.each(func(cb func(A, B, C)) bool { cb(a, b, c); return false })
+}
+
+type Event0R[R any] struct{ typedEvent[func() R] }
+
+func (e *Event0R[R]) Call() (ret R) {
+	var errs []error
+	panics := e.each(func(cb func() R) bool { ret = cb(); return result(ret, &errs) })
+	return finish(ret, errs, panics)
+}
+
+type Event1R[A, R any] struct{ typedEvent[func(A) R] }
+
+func (e *Event1R[A, R]) Call(a A) (ret R) {
+	var errs []error
+	panics := e.each(func(cb func(A) R) bool { ret = cb(a); return result(ret, &errs) })
+	return finish(ret, errs, panics)
+}
+
+type Event2R[A, B, R any] struct{ typedEvent[func(A, B) R] }
+
+func (e *Event2R[A, B, R]) Call(a A, b B) (ret R) {
+	var errs []error
+	panics := e.each(func(cb func(A, B) R) bool { ret = cb(a, b); return result(ret, &errs) })
+	return finish(ret, errs, panics)
+}
+
+type Event3R[A, B, C, R any] struct{ typedEvent[func(A, B, C) R] }
+
+func (e *Event3R[A, B, C, R]) Call(a A, b B, c C) (ret R) {
+	var errs []error
+	panics := e.each(func(cb func(A, B, C) R) bool { ret = cb(a, b, c); return result(ret, &errs) })
+	return finish(ret, errs, panics)
+}
@@ -0,0 +1,314 @@
+package fix64
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrSyntax = errors.New("fix64: invalid syntax")
+	ErrRange  = errors.New("fix64: value out of range")
+)
+
+var pow10 = [...]uint64{
+	1, 10, 100, 1000, 10000, 100000, 1000000, 10000000, 100000000, 1000000000,
+	10000000000, 100000000000, 1000000000000, 10000000000000, 100000000000000,
+	1000000000000000, 10000000000000000, 100000000000000000, 1000000000000000000,
+}
+
+// Parse 解析十进制字符串,如"-12.375",按最近值精确舍入(恰在中间时远离0)
+func Parse(s string) (Fix64, error) {
+	v, err := parseBits(s, fractionalPlaces)
+	return Fix64(v), err
+}
+
+// parseBits 解析为fb位小数的定点数原始值
+func parseBits(s string, fb uint) (int64, error) {
+	str := s
+	neg := false
+	if len(s) > 0 && (s[0] == '+' || s[0] == '-') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	intStr, fracStr := s, ""
+	for i := 0; i < len(s); i++ {
+		if s[i] == '.' {
+			intStr, fracStr = s[:i], s[i+1:]
+			break
+		}
+	}
+	if intStr == "" && fracStr == "" || !isDigits(intStr) || !isDigits(fracStr) {
+		return 0, fmt.Errorf("%w: %q", ErrSyntax, str)
+	}
+	var intPart uint64
+	for i := 0; i < len(intStr); i++ {
+		intPart = intPart*10 + uint64(intStr[i]-'0')
+		if intPart > 1<<(63-fb) {
+			return 0, fmt.Errorf("%w: %q", ErrRange, str)
+		}
+	}
+	raw := intPart<<fb + parseFrac(fracStr, fb)
+	if neg {
+		if raw > 1<<63 {
+			return 0, fmt.Errorf("%w: %q", ErrRange, str)
+		}
+		return int64(-raw), nil
+	}
+	if raw >= 1<<63 {
+		return 0, fmt.Errorf("%w: %q", ErrRange, str)
+	}
+	return int64(raw), nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseFrac 十进制小数部分转为fb位二进制小数,四舍五入,可能进位为1<<fb
+func parseFrac(s string, fb uint) uint64 {
+	for len(s) > 0 && s[len(s)-1] == '0' {
+		s = s[:len(s)-1]
+	}
+	if s == "" {
+		return 0
+	}
+	if len(s) < len(pow10) { // 快速路径,frac<10^18可用128位运算
+		frac, _ := strconv.ParseUint(s, 10, 64)
+		den := pow10[len(s)]
+		q, r := bits.Div64(frac>>(64-fb), frac<<fb, den)
+		if r >= den-r {
+			q++
+		}
+		return q
+	}
+	frac, _ := new(big.Int).SetString(s, 10)
+	den := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(len(s))), nil)
+	q, r := new(big.Int).QuoRem(frac.Lsh(frac, fb), den, new(big.Int))
+	if r.Lsh(r, 1).Cmp(den) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	return q.Uint64()
+}
+
+// MustParse Parse失败时panic,用于常量定义
+func MustParse(s string) Fix64 {
+	f, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// appendDecimal 追加最短的、Parse后能还原为f的十进制表示
+func appendDecimal(b []byte, f Fix64) []byte {
+	return appendDecimalBits(b, int64(f), fractionalPlaces)
+}
+
+// appendDecimalBits fb位小数的定点数v的最短十进制表示,fb<=48
+func appendDecimalBits(b []byte, v int64, fb uint) []byte {
+	u := uint64(v)
+	if v < 0 {
+		u = -u
+		b = append(b, '-')
+	}
+	b = strconv.AppendUint(b, u>>fb, 10)
+	frac := u & (1<<fb - 1)
+	if frac == 0 {
+		return b
+	}
+	// 逐位增加精度,直到能还原
+	for n := 1; n < len(pow10); n++ {
+		// round(frac*10^n / 2^fb)
+		hi, lo := bits.Mul64(frac, pow10[n])
+		d := hi<<(64-fb) | lo>>fb
+		if lo&(1<<fb-1) >= 1<<(fb-1) {
+			d++
+		}
+		if d == pow10[n] {
+			continue //进位到整数部分,精度不够
+		}
+		// d/10^n 是否还原为frac
+		q, r := bits.Div64(d>>(64-fb), d<<fb, pow10[n])
+		if r >= pow10[n]-r {
+			q++
+		}
+		if q == frac {
+			s := strconv.AppendUint(nil, d, 10)
+			b = append(b, '.')
+			for i := len(s); i < n; i++ {
+				b = append(b, '0')
+			}
+			for len(s) > 0 && s[len(s)-1] == '0' {
+				s = s[:len(s)-1]
+			}
+			return append(b, s...)
+		}
+	}
+	return b //不会到这里,fb<=48时18位十进制小数足以区分2^-fb
+}
+
+// Decimal 最短的能精确还原的十进制表示,如"-12.375"
+func (f Fix64) Decimal() string {
+	return string(appendDecimal(nil, f))
+}
+
+func (f Fix64) MarshalText() ([]byte, error) {
+	return appendDecimal(nil, f), nil
+}
+
+func (f *Fix64) UnmarshalText(text []byte) error {
+	v, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*f = v
+	return nil
+}
+
+// MarshalJSON 十进制字符串"1.5",JS等按double解析数字的客户端也不丢精度
+func (f Fix64) MarshalJSON() ([]byte, error) {
+	b := append([]byte{'"'}, appendDecimal(nil, f)...)
+	return append(b, '"'), nil
+}
+
+// UnmarshalJSON 接受十进制字符串和数字,都按十进制值解析,"1"和1.0相同,也接受1.5e3这样的指数形式
+func (f *Fix64) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	v, err := parseExp(s)
+	if err != nil {
+		return err
+	}
+	*f = v
+	return nil
+}
+
+// parseExp 在Parse的基础上接受指数形式,移动小数点后再解析
+func parseExp(s string) (Fix64, error) {
+	mant, expStr, ok := strings.Cut(s, "e")
+	if !ok {
+		mant, expStr, ok = strings.Cut(s, "E")
+	}
+	if !ok {
+		return Parse(s)
+	}
+	fail := func(err error) (Fix64, error) { return 0, fmt.Errorf("%w: %q", err, s) }
+	expNeg := false
+	if len(expStr) > 0 && (expStr[0] == '+' || expStr[0] == '-') {
+		expNeg = expStr[0] == '-'
+		expStr = expStr[1:]
+	}
+	if expStr == "" || !isDigits(expStr) {
+		return fail(ErrSyntax)
+	}
+	exp := 0
+	for i := 0; i < len(expStr); i++ {
+		exp = min(exp*10+int(expStr[i]-'0'), 1<<20) //足够大,结果必然溢出或舍入为0
+	}
+	if expNeg {
+		exp = -exp
+	}
+	sign := ""
+	if len(mant) > 0 && (mant[0] == '+' || mant[0] == '-') {
+		sign, mant = mant[:1], mant[1:]
+	}
+	intStr, fracStr, _ := strings.Cut(mant, ".")
+	if intStr == "" && fracStr == "" || !isDigits(intStr) || !isDigits(fracStr) {
+		return fail(ErrSyntax)
+	}
+	// digits去掉前导0,小数点在digits的第pos位之后
+	all := intStr + fracStr
+	digits := strings.TrimLeft(all, "0")
+	pos := len(intStr) - (len(all) - len(digits)) + exp
+	switch {
+	case digits == "" || pos < -30: //小于1e-30,舍入为0
+		return 0, nil
+	case pos > 20: //不小于1e20
+		return fail(ErrRange)
+	case pos <= 0:
+		digits = "0." + strings.Repeat("0", -pos) + digits
+	case pos >= len(digits):
+		digits += strings.Repeat("0", pos-len(digits))
+	default:
+		digits = digits[:pos] + "." + digits[pos:]
+	}
+	v, err := Parse(sign + digits)
+	if errors.Is(err, ErrRange) {
+		return fail(ErrRange)
+	}
+	return v, err
+}
+
+// RawFix64 JSON编码为原始int64整数的Fix64,如1.5编码为6442450944,精确紧凑,用于两端都是Fix64的内部协议
+// 只接受整数,与十进制的Fix64不会混淆;与Fix64直接转换,如RawFix64(f),Fix64(r)
+type RawFix64 Fix64
+
+func (r RawFix64) MarshalJSON() ([]byte, error) {
+	return strconv.AppendInt(nil, int64(r), 10), nil
+}
+
+func (r *RawFix64) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: RawFix64 %q", ErrSyntax, s)
+	}
+	*r = RawFix64(v)
+	return nil
+}
+
+// MarshalBinary 8字节大端原始值
+func (f Fix64) MarshalBinary() ([]byte, error) {
+	return binary.BigEndian.AppendUint64(nil, uint64(f)), nil
+}
+
+func (f *Fix64) UnmarshalBinary(data []byte) error {
+	if len(data) != 8 {
+		return fmt.Errorf("fix64: UnmarshalBinary: want 8 bytes, got %d", len(data))
+	}
+	*f = Fix64(binary.BigEndian.Uint64(data))
+	return nil
+}
+
+// Value 以原始int64存入数据库,精确且可排序
+func (f Fix64) Value() (driver.Value, error) {
+	return int64(f), nil
+}
+
+// Scan int64按原始值,float64按FromFloat,字符串按十进制解析,NULL为0
+func (f *Fix64) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*f = FixZero
+	case int64:
+		*f = Fix64(v)
+	case float64:
+		*f = FromFloat(v)
+	case []byte:
+		return f.UnmarshalText(v)
+	case string:
+		return f.UnmarshalText([]byte(v))
+	default:
+		return fmt.Errorf("fix64: cannot scan %T", src)
+	}
+	return nil
+}
@@ -0,0 +1,327 @@
+package fix64
+
+import (
+	"math"
+	"math/bits"
+)
+
+// 可配置精度的定点数,按子系统选精度:
+//
+//	Q48_16 世界坐标,整数范围±1.4e14,精度1.5e-5
+//	Q32_32 与Fix64相同,可无损互转
+//	Q16_16 int32存储,省内存,整数范围±32768
+//
+// 乘除舍入方式与Fix64一致,三角函数转为Fix64计算后再转回
+// 溢出处理也与Fix64一致:加减乘除溢出时回绕(Fixed32回绕到int32),以-tags fix64debug构建时panic;
+// Q32_32的运算结果与Fix64逐位相同
+
+// Precision 小数位数标记,可自定义: type P20 struct{}; func (P20) FracBits() uint { return 20 }
+type Precision interface {
+	FracBits() uint
+}
+
+type (
+	P8  struct{}
+	P16 struct{}
+	P24 struct{}
+	P32 struct{}
+)
+
+func (P8) FracBits() uint  { return 8 }
+func (P16) FracBits() uint { return 16 }
+func (P24) FracBits() uint { return 24 }
+func (P32) FracBits() uint { return 32 }
+
+// Fixed int64存储,P位小数
+type Fixed[P Precision] int64
+
+// Fixed32 int32存储,P位小数
+type Fixed32[P Precision] int32
+
+type (
+	Q48_16 = Fixed[P16]
+	Q40_24 = Fixed[P24]
+	Q32_32 = Fixed[P32]
+	Q16_16 = Fixed32[P16]
+	Q24_8  = Fixed32[P8]
+)
+
+// FixedValue 各精度定点数的公共接口,用于相互转换
+type FixedValue interface {
+	Raw() int64
+	FracBits() uint
+}
+
+func fracBits[P Precision]() uint {
+	var p P
+	return p.FracBits()
+}
+
+// convertRaw 把fb位小数的原始值v转为to位小数,四舍五入,返回是否无损
+func convertRaw(v int64, fb, to uint) (int64, bool) {
+	if to >= fb {
+		shift := to - fb
+		if shift > 0 && (v > math.MaxInt64>>shift || v < math.MinInt64>>shift) {
+			if v < 0 {
+				return math.MinInt64, false
+			}
+			return math.MaxInt64, false
+		}
+		return v << shift, true
+	}
+	shift := fb - to
+	ret := v>>shift + v>>(shift-1)&1 // 四舍五入,与Fix64.Mul一致向正无穷
+	return ret, ret<<shift == v
+}
+
+// saturate32 int64原始值钳制到int32,返回是否未溢出
+func saturate32(v int64) (int32, bool) {
+	if v > math.MaxInt32 {
+		return math.MaxInt32, false
+	}
+	if v < math.MinInt32 {
+		return math.MinInt32, false
+	}
+	return int32(v), true
+}
+
+// mulRaw fb位小数的定点乘法,四舍五入,溢出时回绕,ok与Fix64.MulChecked相同
+func mulRaw(a, b int64, fb uint) (ret int64, ok bool) {
+	hi, lo := bits.Mul64(uint64(a), uint64(b))
+	// 有符号128位积的高位修正
+	if a < 0 {
+		hi -= uint64(b)
+	}
+	if b < 0 {
+		hi -= uint64(a)
+	}
+	ret = int64(hi<<(64-fb) | lo>>fb)
+	// 128位积右移fb位后需能放进int64: hi右移fb-1位后只剩符号位
+	ok = int64(hi)>>(fb-1) == 0 || int64(hi)>>(fb-1) == -1
+	if lo>>(fb-1)&1 != 0 { // 四舍五入进位
+		ok = ok && ret != math.MaxInt64
+		ret++
+	}
+	return ret, ok
+}
+
+// divRaw fb位小数的定点除法,绝对值四舍五入,溢出时回绕,除数为0返回0,ok与Fix64.DivChecked相同
+func divRaw(a, b int64, fb uint) (int64, bool) {
+	if b == 0 {
+		return 0, false
+	}
+	n, d := absU64(Fix64(a)), absU64(Fix64(b))
+	hi, lo := n>>(64-fb), n<<fb
+	ok := hi < d
+	// 先约去高位的整数倍,只保留商的低64位
+	q, r := bits.Div64(hi%d, lo, d)
+	if r >= d-r {
+		q++
+	}
+	if (a < 0) != (b < 0) {
+		return int64(-q), ok && q <= 1<<63
+	}
+	return int64(q), ok && q < 1<<63
+}
+
+// sqrtRaw fb位小数的精确平方根,四舍五入,负数返回0
+func sqrtRaw(a int64, fb uint) int64 {
+	if a <= 0 {
+		return 0
+	}
+	lo := uint64(a) << fb
+	s := isqrt128(uint64(a)>>(64-fb), lo)
+	// 余数(小于2^64)大于s时精确值不小于s+0.5
+	if _, sq := bits.Mul64(s, s); lo-sq > s {
+		s++
+	}
+	return int64(s)
+}
+
+// Raw 原始值
+func (f Fix64) Raw() int64 { return int64(f) }
+
+// FracBits 小数位数
+func (f Fix64) FracBits() uint { return fractionalPlaces }
+
+// FromFixed 转为Fix64,返回是否无损
+func FromFixed(v FixedValue) (Fix64, bool) {
+	r, ok := convertRaw(v.Raw(), v.FracBits(), fractionalPlaces)
+	return Fix64(r), ok
+}
+
+// Convert 转为P位小数的Fixed,返回是否无损,溢出时钳制
+func Convert[P Precision](v FixedValue) (Fixed[P], bool) {
+	r, ok := convertRaw(v.Raw(), v.FracBits(), fracBits[P]())
+	return Fixed[P](r), ok
+}
+
+// Convert32 转为P位小数的Fixed32,返回是否无损,溢出时钳制
+func Convert32[P Precision](v FixedValue) (Fixed32[P], bool) {
+	r, ok := convertRaw(v.Raw(), v.FracBits(), fracBits[P]())
+	r32, fit := saturate32(r)
+	return Fixed32[P](r32), ok && fit
+}
+
+func FixedFromInt[P Precision](v int64) Fixed[P] {
+	return Fixed[P](v << fracBits[P]())
+}
+
+func FixedFromFloat[P Precision](v float64) Fixed[P] {
+	return Fixed[P](roundFloat(v * float64(uint64(1)<<fracBits[P]())))
+}
+
+// ParseFixed 解析十进制字符串,规则同Parse
+func ParseFixed[P Precision](s string) (Fixed[P], error) {
+	v, err := parseBits(s, fracBits[P]())
+	return Fixed[P](v), err
+}
+
+func Fixed32FromInt[P Precision](v int32) Fixed32[P] {
+	return Fixed32[P](v << fracBits[P]())
+}
+
+func Fixed32FromFloat[P Precision](v float64) Fixed32[P] {
+	return Fixed32[P](int32(roundFloat(v * float64(uint64(1)<<fracBits[P]()))))
+}
+
+// ParseFixed32 解析十进制字符串,规则同Parse,超出int32范围返回ErrRange
+func ParseFixed32[P Precision](s string) (Fixed32[P], error) {
+	v, err := parseBits(s, fracBits[P]())
+	if err != nil {
+		return 0, err
+	}
+	v32, ok := saturate32(v)
+	if !ok {
+		return 0, ErrRange
+	}
+	return Fixed32[P](v32), nil
+}
+
+func roundFloat(t float64) int64 {
+	if t > 0 {
+		t += 0.5
+	} else if t < 0 {
+		t -= 0.5
+	}
+	return int64(t)
+}
+
+func (f Fixed[P]) Raw() int64     { return int64(f) }
+func (f Fixed[P]) FracBits() uint { return fracBits[P]() }
+func (f Fixed[P]) Int64() int64   { return int64(f) >> fracBits[P]() }
+func (f Fixed[P]) Float64() float64 {
+	return float64(f) / float64(uint64(1)<<fracBits[P]())
+}
+func (f Fixed[P]) String() string { return string(appendDecimalBits(nil, int64(f), fracBits[P]())) }
+
+// Fix64 转为Fix64,有损时四舍五入,溢出时钳制
+func (f Fixed[P]) Fix64() Fix64 {
+	v, _ := FromFixed(f)
+	return v
+}
+
+func (f Fixed[P]) fromFix64(v Fix64) Fixed[P] {
+	r, _ := Convert[P](v)
+	return r
+}
+
+// Add 加减与比例无关,直接用Fix64的
+func (f Fixed[P]) Add(val Fixed[P]) Fixed[P] { return Fixed[P](Fix64(f).Add(Fix64(val))) }
+func (f Fixed[P]) Sub(val Fixed[P]) Fixed[P] { return Fixed[P](Fix64(f).Sub(Fix64(val))) }
+func (f Fixed[P]) Mul(val Fixed[P]) Fixed[P] {
+	ret, ok := mulRaw(int64(f), int64(val), fracBits[P]())
+	if debugOverflow && !ok {
+		overflowPanic("*", Fix64(f), Fix64(val))
+	}
+	return Fixed[P](ret)
+}
+func (f Fixed[P]) Div(val Fixed[P]) Fixed[P] {
+	ret, ok := divRaw(int64(f), int64(val), fracBits[P]())
+	if debugOverflow && !ok && val != 0 {
+		overflowPanic("/", Fix64(f), Fix64(val))
+	}
+	return Fixed[P](ret)
+}
+func (f Fixed[P]) Abs() Fixed[P] {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
+func (f Fixed[P]) Floor() int64 { return int64(f) >> fracBits[P]() }
+func (f Fixed[P]) Ceil() int64  { return (int64(f) + 1<<fracBits[P]() - 1) >> fracBits[P]() }
+func (f Fixed[P]) Round() int64 { return (int64(f) + 1<<(fracBits[P]()-1)) >> fracBits[P]() }
+func (f Fixed[P]) Sqrt() Fixed[P] {
+	return Fixed[P](sqrtRaw(int64(f), fracBits[P]()))
+}
+func (f Fixed[P]) Sin() Fixed[P]  { return f.fromFix64(f.Fix64().Sin()) }
+func (f Fixed[P]) Cos() Fixed[P]  { return f.fromFix64(f.Fix64().Cos()) }
+func (f Fixed[P]) Tan() Fixed[P]  { return f.fromFix64(f.Fix64().Tan()) }
+func (f Fixed[P]) Atan() Fixed[P] { return f.Atan2(FixedFromInt[P](1)) }
+
+// Atan2 f/x的方位角,与比例无关,直接用原始值计算
+func (f Fixed[P]) Atan2(x Fixed[P]) Fixed[P] {
+	return f.fromFix64(Atan2(Fix64(f), Fix64(x)))
+}
+
+func (f Fixed32[P]) Raw() int64     { return int64(f) }
+func (f Fixed32[P]) FracBits() uint { return fracBits[P]() }
+func (f Fixed32[P]) Int64() int64   { return int64(f) >> fracBits[P]() }
+func (f Fixed32[P]) Float64() float64 {
+	return float64(f) / float64(uint64(1)<<fracBits[P]())
+}
+func (f Fixed32[P]) String() string { return string(appendDecimalBits(nil, int64(f), fracBits[P]())) }
+
+// Fix64 转为Fix64,有损时四舍五入,溢出时钳制
+func (f Fixed32[P]) Fix64() Fix64 {
+	v, _ := FromFixed(f)
+	return v
+}
+
+func (f Fixed32[P]) fromFix64(v Fix64) Fixed32[P] {
+	r, _ := Convert32[P](v)
+	return r
+}
+
+// fromRaw 64位中间结果截断为int32,与int32运算一样回绕,超出int32时fix64debug下panic
+func (f Fixed32[P]) fromRaw(op string, v int64, val Fixed32[P]) Fixed32[P] {
+	if debugOverflow && int64(int32(v)) != v {
+		overflowPanic(op, Fix64(f), Fix64(val))
+	}
+	return Fixed32[P](int32(v))
+}
+
+func (f Fixed32[P]) Add(val Fixed32[P]) Fixed32[P] { return f.fromRaw("+", int64(f)+int64(val), val) }
+func (f Fixed32[P]) Sub(val Fixed32[P]) Fixed32[P] { return f.fromRaw("-", int64(f)-int64(val), val) }
+
+// Mul int32的积和商在int64内不会溢出,只需检查截断
+func (f Fixed32[P]) Mul(val Fixed32[P]) Fixed32[P] {
+	ret, _ := mulRaw(int64(f), int64(val), fracBits[P]())
+	return f.fromRaw("*", ret, val)
+}
+func (f Fixed32[P]) Div(val Fixed32[P]) Fixed32[P] {
+	ret, _ := divRaw(int64(f), int64(val), fracBits[P]())
+	return f.fromRaw("/", ret, val)
+}
+func (f Fixed32[P]) Abs() Fixed32[P] {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
+func (f Fixed32[P]) Floor() int64 { return int64(f) >> fracBits[P]() }
+func (f Fixed32[P]) Ceil() int64  { return (int64(f) + 1<<fracBits[P]() - 1) >> fracBits[P]() }
+func (f Fixed32[P]) Round() int64 { return (int64(f) + 1<<(fracBits[P]()-1)) >> fracBits[P]() }
+func (f Fixed32[P]) Sqrt() Fixed32[P] {
+	return f.fromRaw("sqrt", sqrtRaw(int64(f), fracBits[P]()), 0)
+}
+func (f Fixed32[P]) Sin() Fixed32[P]  { return f.fromFix64(f.Fix64().Sin()) }
+func (f Fixed32[P]) Cos() Fixed32[P]  { return f.fromFix64(f.Fix64().Cos()) }
+func (f Fixed32[P]) Tan() Fixed32[P]  { return f.fromFix64(f.Fix64().Tan()) }
+func (f Fixed32[P]) Atan() Fixed32[P] { return f.Atan2(Fixed32FromInt[P](1)) }
+
+// Atan2 f/x的方位角,与比例无关,直接用原始值计算
+func (f Fixed32[P]) Atan2(x Fixed32[P]) Fixed32[P] {
+	return f.fromFix64(Atan2(Fix64(f), Fix64(x)))
+}
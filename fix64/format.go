@@ -0,0 +1,181 @@
+package fix64
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+)
+
+// RoundingMode 十进制舍入方式,均按绝对值舍入,正负对称
+type RoundingMode int
+
+const (
+	RoundHalfEven   RoundingMode = iota //四舍六入,恰在中间时取偶数,与fmt对float64的%f一致
+	RoundHalfUp                         //四舍五入,恰在中间时远离0
+	RoundTowardZero                     //截断
+)
+
+// roundUp 已截断的末位为last,余数rem与half比较后是否进位
+func (m RoundingMode) roundUp(last byte, rem, half uint64) bool {
+	switch m {
+	case RoundHalfEven:
+		return rem > half || rem == half && (last-'0')&1 == 1
+	case RoundHalfUp:
+		return rem >= half
+	}
+	return false
+}
+
+// Format 保留prec位小数的十进制表示,不足补0,如Format(f, 2, RoundHalfEven)得"-12.38"
+// prec<0时同Decimal,取最短的能还原的表示;舍入后为0时不带负号
+func Format(v FixedValue, prec int, mode RoundingMode) string {
+	return string(AppendFormat(nil, v, prec, mode))
+}
+
+// AppendFormat 把Format的结果追加到b
+func AppendFormat(b []byte, v FixedValue, prec int, mode RoundingMode) []byte {
+	if prec < 0 {
+		return appendDecimalBits(b, v.Raw(), v.FracBits())
+	}
+	return appendFixedBits(b, v.Raw(), v.FracBits(), prec, mode)
+}
+
+// appendFixedBits fb位小数的定点数v保留prec位小数,fb<=48
+func appendFixedBits(b []byte, v int64, fb uint, prec int, mode RoundingMode) []byte {
+	u := uint64(v)
+	if v < 0 {
+		u = -u
+	}
+	ip, frac := u>>fb, u&(1<<fb-1)
+	// 逐位生成小数,定点小数的十进制展开是有限的,超出fb位后全为0
+	digits := make([]byte, prec)
+	for i := range digits {
+		frac *= 10
+		digits[i] = byte('0' + frac>>fb)
+		frac &= 1<<fb - 1
+	}
+	last := byte('0' + ip%10)
+	if prec > 0 {
+		last = digits[prec-1]
+	}
+	if frac != 0 && mode.roundUp(last, frac, 1<<(fb-1)) {
+		i := prec - 1
+		for ; i >= 0 && digits[i] == '9'; i-- {
+			digits[i] = '0'
+		}
+		if i >= 0 {
+			digits[i]++
+		} else {
+			ip++
+		}
+	}
+	if v < 0 && (ip != 0 || !allZero(digits)) {
+		b = append(b, '-')
+	}
+	b = strconv.AppendUint(b, ip, 10)
+	if prec > 0 {
+		b = append(b, '.')
+		b = append(b, digits...)
+	}
+	return b
+}
+
+func allZero(digits []byte) bool {
+	for _, d := range digits {
+		if d != '0' {
+			return false
+		}
+	}
+	return true
+}
+
+// RoundTo 按RoundHalfEven舍入到n位小数,返回最接近该十进制数的Fix64,与Format(f, n, RoundHalfEven)显示一致
+// n<0时按0处理,n>=10时Fix64的精度已不足以区分,原样返回;溢出时钳制到FixMax/FixMin
+func (f Fix64) RoundTo(n int) Fix64 {
+	return f.RoundToMode(n, RoundHalfEven)
+}
+
+// RoundToMode 按指定方式舍入到n位小数,见RoundTo
+func (f Fix64) RoundToMode(n int, mode RoundingMode) Fix64 {
+	n = max(n, 0)
+	if n >= 10 {
+		return f
+	}
+	u := absU64(f)
+	ip, frac := u>>fractionalPlaces, u&mask
+	// q = frac*10^n / 2^32, 截断后按mode进位
+	hi, lo := bits.Mul64(frac, pow10[n])
+	q := hi<<(64-fractionalPlaces) | lo>>fractionalPlaces
+	rem := lo & mask
+	last := byte('0' + q%10)
+	if n == 0 {
+		last = byte('0' + ip%10)
+	}
+	if rem != 0 && mode.roundUp(last, rem, 1<<(fractionalPlaces-1)) {
+		q++
+	}
+	// 还原: ip + q/10^n, 四舍五入到最近的Fix64
+	fq, r := bits.Div64(q>>(64-fractionalPlaces), q<<fractionalPlaces, pow10[n])
+	if r >= pow10[n]-r {
+		fq++
+	}
+	m := ip<<fractionalPlaces + fq
+	if f < 0 {
+		return saturate(Fix64(-m), m <= 1<<63, true)
+	}
+	return saturate(Fix64(m), m < 1<<63, false)
+}
+
+// Format 实现fmt.Formatter:
+// %f %F 按RoundHalfEven保留精度位小数,默认6位,支持宽度和+ -0空格标志
+// %v %s %q 同String,%#v及其余动词按原始int64格式化
+func (f Fix64) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'f', 'F':
+		prec, ok := s.Precision()
+		if !ok {
+			prec = 6
+		}
+		num := appendFixedBits(nil, int64(f), fractionalPlaces, prec, RoundHalfEven)
+		var sign []byte
+		if num[0] == '-' {
+			sign, num = num[:1], num[1:]
+		} else if s.Flag('+') {
+			sign = []byte{'+'}
+		} else if s.Flag(' ') {
+			sign = []byte{' '}
+		}
+		pad := 0
+		if w, ok := s.Width(); ok {
+			pad = max(w-len(sign)-len(num), 0)
+		}
+		switch {
+		case s.Flag('-'):
+			s.Write(sign)
+			s.Write(num)
+			writeRepeat(s, ' ', pad)
+		case s.Flag('0'):
+			s.Write(sign)
+			writeRepeat(s, '0', pad)
+			s.Write(num)
+		default:
+			writeRepeat(s, ' ', pad)
+			s.Write(sign)
+			s.Write(num)
+		}
+	case 'v', 's', 'q':
+		if verb == 'v' && s.Flag('#') {
+			fmt.Fprintf(s, fmt.FormatString(s, verb), int64(f))
+			return
+		}
+		fmt.Fprintf(s, fmt.FormatString(s, verb), f.String())
+	default:
+		fmt.Fprintf(s, fmt.FormatString(s, verb), int64(f))
+	}
+}
+
+func writeRepeat(s fmt.State, c byte, n int) {
+	for ; n > 0; n-- {
+		s.Write([]byte{c})
+	}
+}
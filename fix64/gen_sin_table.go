@@ -0,0 +1,84 @@
+//go:build ignore
+
+// 生成sin_table.go: go run gen_sin_table.go -n 256
+// n为四分之一周期的分段数,须为2的幂且>=16,越大查表后的泰勒修正项越小,但精度由最终舍入决定,基本不变
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"math/big"
+	"os"
+)
+
+const prec = 256
+
+// halfPi Pi/2,60位十进制
+const halfPi = "1.570796326794896619231321691639751442098584699687552910487472"
+
+func main() {
+	n := flag.Int("n", 256, "四分之一周期的分段数")
+	out := flag.String("o", "sin_table.go", "输出文件")
+	flag.Parse()
+	if *n < 16 || *n&(*n-1) != 0 {
+		log.Fatalf("n must be a power of two >= 16, got %d", *n)
+	}
+
+	q62 := new(big.Float).SetPrec(prec).SetMantExp(big.NewFloat(1), 62)
+	hp, _ := new(big.Float).SetPrec(prec).SetString(halfPi)
+	// 步长截断到62位小数,表项按截断后的步长计算,查表时不引入额外误差
+	step, _ := new(big.Float).Quo(new(big.Float).Mul(hp, q62), big.NewFloat(float64(*n))).Int(nil)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by gen_sin_table.go; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package fix64\n\n")
+	fmt.Fprintf(&b, "// sinStepQ62 表项间隔,约为Pi/2/%d,62位小数\n", *n)
+	fmt.Fprintf(&b, "const sinStepQ62 = %d\n\n", step)
+	fmt.Fprintf(&b, "// sinTableQ62 sin(i*sinStepQ62),62位小数,cos取sinTableQ62[len-1-i]\n")
+	fmt.Fprintf(&b, "var sinTableQ62 = [...]uint64{\n")
+	for i := 0; i <= *n; i++ {
+		x := new(big.Float).SetPrec(prec).SetInt(new(big.Int).Mul(step, big.NewInt(int64(i))))
+		x.Quo(x, q62)
+		v := sin(x)
+		v.Mul(v, q62)
+		v.Add(v, big.NewFloat(0.5))
+		u, _ := v.Int(nil)
+		if i%4 == 0 {
+			b.WriteString("\t")
+		}
+		fmt.Fprintf(&b, "0x%016x,", u)
+		if i%4 == 3 || i == *n {
+			b.WriteString("\n")
+		} else {
+			b.WriteString(" ")
+		}
+	}
+	b.WriteString("}\n")
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// sin 泰勒级数,x∈[0,Pi/2]
+func sin(x *big.Float) *big.Float {
+	x2 := new(big.Float).SetPrec(prec).Mul(x, x)
+	sum := new(big.Float).SetPrec(prec).Set(x)
+	term := new(big.Float).SetPrec(prec).Set(x)
+	eps := new(big.Float).SetMantExp(big.NewFloat(1), -150)
+	for k := int64(1); ; k++ {
+		term.Mul(term, x2)
+		term.Quo(term, big.NewFloat(float64((2*k)*(2*k+1))))
+		term.Neg(term)
+		sum.Add(sum, term)
+		if new(big.Float).Abs(term).Cmp(eps) < 0 {
+			return sum
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package fix64
+
+import "math/bits"
+
+// 反三角、指数和对数函数,只用整数运算,结果在各平台逐位一致
+// 误差上界(与math包对同一输入的float64结果相比,随机采样20万次测得):
+//
+//	Atan/Atan2/Asin/Acos  绝对误差 <= 1.5e-9
+//	Exp                   相对误差 <= 2.5e-9,结果<1时为绝对误差
+//	Log/Log2              绝对误差 <= 5e-10
+//	Pow                   相对误差约为 2.5e-9*(1+|y*Log(x)|),y为整数时为连乘的舍入误差
+
+const (
+	ln2      = int64(2977044472)           //ln(2)
+	ln2Q48   = int64(195103586505167)      //ln(2),48位小数
+	log2eQ61 = uint64(3326628274461080623) //1/ln(2),61位小数
+
+	FixMax = Fix64(1<<63 - 1)
+	FixMin = Fix64(-1 << 63)
+)
+
+// cordicAtan atan(2^-i)
+var cordicAtan = [...]int64{
+	3373259426, 1991351318, 1052175346, 534100635, 268086748, 134174063, 67103403, 33553749,
+	16777131, 8388597, 4194303, 2097152, 1048576, 524288, 262144, 131072,
+	65536, 32768, 16384, 8192, 4096, 2048, 1024, 512,
+	256, 128, 64, 32, 16, 8, 4, 2, 1,
+}
+
+// Atan2 y/x的方位角,范围[-Pi,Pi],x,y都为0时返回0,坐标轴方向上结果精确
+func Atan2(y, x Fix64) Fix64 {
+	switch {
+	case y == 0 && x >= 0:
+		return FixZero
+	case y == 0:
+		return FixPi
+	case x == 0 && y > 0:
+		return FixHalfPi
+	case x == 0:
+		return -FixHalfPi
+	}
+	// 同比例缩放到最高位在第60位,提高精度并留出CORDIC增益(约1.65)的余量
+	ux, uy := uint64(x), uint64(y)
+	if x < 0 {
+		ux = -ux
+	}
+	if y < 0 {
+		uy = -uy
+	}
+	shift := bits.Len64(ux|uy) - 60
+	vx, vy := int64(x), int64(y)
+	if shift > 0 {
+		vx >>= shift
+		vy >>= shift
+	} else {
+		vx <<= -shift
+		vy <<= -shift
+	}
+
+	// 先旋转到右半平面
+	var z int64
+	if vx < 0 {
+		if vy >= 0 {
+			vx, vy, z = vy, -vx, halfPi
+		} else {
+			vx, vy, z = -vy, vx, -halfPi
+		}
+	}
+	for i, a := range cordicAtan {
+		if vy > 0 {
+			vx, vy, z = vx+vy>>i, vy-vx>>i, z+a
+		} else {
+			vx, vy, z = vx-vy>>i, vy+vx>>i, z-a
+		}
+	}
+	z = min(max(z, -pi), pi)
+	return Fix64(z)
+}
+
+// Atan 反正切,范围[-Pi/2,Pi/2]
+func (f Fix64) Atan() Fix64 {
+	return Atan2(f, FixOne)
+}
+
+// Asin 反正弦,范围[-Pi/2,Pi/2],f超出[-1,1]时按边界值计算
+func (f Fix64) Asin() Fix64 {
+	f = clampUnit(f)
+	if f == FixOne || f == -FixOne {
+		return Atan2(f, FixZero)
+	}
+	return Atan2(f, sqrt1mx2(f))
+}
+
+// Acos 反余弦,范围[0,Pi],f超出[-1,1]时按边界值计算
+func (f Fix64) Acos() Fix64 {
+	f = clampUnit(f)
+	return Atan2(sqrt1mx2(f), f)
+}
+
+func clampUnit(f Fix64) Fix64 {
+	if f > FixOne {
+		return FixOne
+	}
+	if f < -FixOne {
+		return -FixOne
+	}
+	return f
+}
+
+// sqrt1mx2 sqrt(1-f*f),|f|<=1,(1-f)(1+f)按128位精确计算
+func sqrt1mx2(f Fix64) Fix64 {
+	hi, lo := bits.Mul64(uint64(FixOne-f), uint64(FixOne+f))
+	return Fix64(isqrt128(hi, lo))
+}
+
+// isqrt128 128位整数平方根向下取整,结果需小于2^63
+func isqrt128(hi, lo uint64) uint64 {
+	if hi == 0 && lo == 0 {
+		return 0
+	}
+	n := bits.Len64(lo)
+	if hi != 0 {
+		n = 64 + bits.Len64(hi)
+	}
+	r := uint64(1) << ((n + 1) / 2) //不小于真值
+	for {
+		q, _ := bits.Div64(hi, lo, r)
+		next := (r + q) >> 1
+		if next >= r {
+			return r
+		}
+		r = next
+	}
+}
+
+// Exp e的f次方,结果超出范围时返回FixMax
+func (f Fix64) Exp() Fix64 {
+	if f == 0 {
+		return FixOne
+	}
+	// ln(2^31)≈21.49, ln(2^-33)≈-22.87
+	if f >= Fix64(92288378626) {
+		return FixMax
+	}
+	if f <= Fix64(-98242467570) {
+		return FixZero
+	}
+	// f = k*ln2 + r, |r| <= ln2/2
+	k := (int64(f) + ln2/2) / ln2
+	if (int64(f)+ln2/2)%ln2 < 0 {
+		k-- //向下取整
+	}
+	r := Fix64(int64(f) - k*ln2)
+	// 泰勒展开 e^r = 1 + r + r^2/2! + ...
+	sum, term := FixOne, FixOne
+	for n := int64(1); n <= 14 && term != 0; n++ {
+		term = term.Mul(r) / Fix64(n)
+		sum += term
+	}
+	if k >= 0 {
+		if bits.Len64(uint64(sum))+int(k) > 63 {
+			return FixMax
+		}
+		return sum << k
+	}
+	return (sum + 1<<(-k-1)) >> -k //四舍五入
+}
+
+// Log 自然对数,f<=0时返回FixMin
+func (f Fix64) Log() Fix64 {
+	if f <= 0 {
+		return FixMin
+	}
+	n, lnm := logSplit(f)
+	return roundQ48(n*ln2Q48 + int64(lnm>>14))
+}
+
+// Log2 以2为底的对数,f<=0时返回FixMin
+func (f Fix64) Log2() Fix64 {
+	if f <= 0 {
+		return FixMin
+	}
+	n, lnm := logSplit(f)
+	hi, _ := bits.Mul64(lnm, log2eQ61) // 62+61=123位小数,右移75位到48位小数
+	return roundQ48(n<<48 + int64(hi>>11))
+}
+
+// roundQ48 48位小数四舍五入到Fix64
+func roundQ48(v int64) Fix64 {
+	return Fix64((v + 1<<15) >> 16)
+}
+
+// logSplit f = m*2^n, m∈[1,2),返回n和ln(m),ln(m)为62位小数
+func logSplit(f Fix64) (int64, uint64) {
+	n := int64(bits.Len64(uint64(f))) - (fractionalPlaces + 1)
+	m := uint64(f)
+	if n > 0 {
+		m >>= n
+	} else {
+		m <<= -n
+	}
+	// ln(m) = 2*atanh(s), s = (m-1)/(m+1) ∈ [0,1/3)
+	s, _ := bits.Div64((m-one)>>2, (m-one)<<62, m+one)
+	s2 := mulQ62(s, s)
+	sum, term := s, s
+	for k := uint64(3); term != 0; k += 2 {
+		term = mulQ62(term, s2)
+		sum += term / k
+	}
+	return n, sum << 1
+}
+
+// mulQ62 62位小数定点乘法
+func mulQ62(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return hi<<2 | lo>>62
+}
+
+// Pow f的y次方
+// y为整数时用连乘,其余用Exp(y*Log(f));结果超出范围时钳制到FixMax/FixMin,太小时为0
+// f<0且y不是整数时返回0,f为0且y<0时返回FixMax
+func (f Fix64) Pow(y Fix64) Fix64 {
+	if y == 0 {
+		return FixOne
+	}
+	if f == 0 {
+		if y < 0 {
+			return FixMax
+		}
+		return FixZero
+	}
+	if y&mask == 0 {
+		return powInt(f, int64(y)>>fractionalPlaces)
+	}
+	if f < 0 {
+		return FixZero
+	}
+	// y*ln(f)超出范围时Exp的结果也必然超出范围: 正的为FixMax,负的下溢为0
+	e, ok := y.MulChecked(f.Log())
+	if !ok {
+		if (y < 0) != (f < FixOne) {
+			return FixZero
+		}
+		return FixMax
+	}
+	return e.Exp()
+}
+
+// powInt 整数次方,溢出时钳制到FixMax/FixMin;负次方的结果太小时为0
+func powInt(f Fix64, n int64) Fix64 {
+	negative := f < 0 && n&1 == 1
+	if n < 0 {
+		n = -n
+		// |f|<1时先取倒数再连乘,避免f^n下溢为0后再求倒数
+		if f > -FixOne && f < FixOne {
+			r, ok := FixOne.DivChecked(f)
+			if !ok {
+				return saturate(r, false, negative)
+			}
+			return powInt(r, n)
+		}
+		p := powInt(f, n)
+		if p == FixMax || p == FixMin {
+			return FixZero
+		}
+		return FixOne.Div(p)
+	}
+	ret := FixOne
+	for n > 0 {
+		var ok bool
+		if n&1 == 1 {
+			if ret, ok = ret.MulChecked(f); !ok {
+				return saturate(ret, false, negative)
+			}
+		}
+		n >>= 1
+		if n > 0 {
+			if f, ok = f.MulChecked(f); !ok {
+				return saturate(f, false, negative)
+			}
+		}
+	}
+	return ret
+}
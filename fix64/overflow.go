@@ -0,0 +1,98 @@
+package fix64
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// 溢出检查和饱和运算
+// XxxChecked 返回结果和是否未溢出,溢出时结果为回绕后的值(与普通运算一致)
+// XxxSat     溢出时结果钳制到FixMax/FixMin
+
+func (f Fix64) AddChecked(val Fix64) (Fix64, bool) {
+	ret := f + val
+	// 同号相加结果变号即溢出
+	return ret, (f >= 0) != (val >= 0) || (ret >= 0) == (f >= 0)
+}
+
+func (f Fix64) SubChecked(val Fix64) (Fix64, bool) {
+	ret := f - val
+	return ret, (f >= 0) == (val >= 0) || (ret >= 0) == (f >= 0)
+}
+
+func (f Fix64) MulChecked(val Fix64) (Fix64, bool) {
+	lo, hi := muli64(int64(f), int64(val))
+	ret := Fix64(hi<<32 | lo>>32)
+	// 128位积右移32位后需能放进int64: hi的高33位都是符号位
+	ok := int64(hi)>>31 == 0 || int64(hi)>>31 == -1
+	if (lo>>31)&1 != 0 { // 四舍五入进位
+		ok = ok && ret != FixMax
+		ret++
+	}
+	return ret, ok
+}
+
+func (f Fix64) DivChecked(val Fix64) (Fix64, bool) {
+	if val == 0 {
+		return FixZero, false
+	}
+	n, d := absU64(f), absU64(val)
+	hi, lo := n>>(64-fractionalPlaces), n<<fractionalPlaces
+	if hi >= d {
+		return f.div(val), false
+	}
+	q, r := bits.Div64(hi, lo, d)
+	if r >= d-r { // 四舍五入
+		q++
+	}
+	if (f < 0) != (val < 0) {
+		return Fix64(-q), q <= 1<<63
+	}
+	return Fix64(q), q < 1<<63
+}
+
+func absU64(f Fix64) uint64 {
+	if f < 0 {
+		return -uint64(f)
+	}
+	return uint64(f)
+}
+
+// saturate 溢出时按真实结果的符号钳制
+func saturate(ret Fix64, ok, negative bool) Fix64 {
+	if ok {
+		return ret
+	}
+	if negative {
+		return FixMin
+	}
+	return FixMax
+}
+
+func (f Fix64) AddSat(val Fix64) Fix64 {
+	ret, ok := f.AddChecked(val)
+	return saturate(ret, ok, f < 0)
+}
+
+func (f Fix64) SubSat(val Fix64) Fix64 {
+	ret, ok := f.SubChecked(val)
+	return saturate(ret, ok, f < 0)
+}
+
+func (f Fix64) MulSat(val Fix64) Fix64 {
+	ret, ok := f.MulChecked(val)
+	return saturate(ret, ok, (f < 0) != (val < 0))
+}
+
+// DivSat 除数为0时按被除数符号钳制,0/0为0
+func (f Fix64) DivSat(val Fix64) Fix64 {
+	if val == 0 && f == 0 {
+		return FixZero
+	}
+	ret, ok := f.DivChecked(val)
+	return saturate(ret, ok, (f < 0) != (val < 0))
+}
+
+func overflowPanic(op string, a, b Fix64) {
+	panic(fmt.Sprintf("fix64: %s overflow: %d %s %d", op, int64(a), op, int64(b)))
+}
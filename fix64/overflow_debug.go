@@ -0,0 +1,6 @@
+//go:build fix64debug
+
+package fix64
+
+// debugOverflow 以-tags fix64debug构建时,Add/Sub/Mul/Div溢出会panic
+const debugOverflow = true
@@ -0,0 +1,75 @@
+// Code generated by gen_sin_table.go; DO NOT EDIT.
+
+package fix64
+
+// sinStepQ62 表项间隔,约为Pi/2/256,62位小数
+const sinStepQ62 = 28296951008113761
+
+// sinTableQ62 sin(i*sinStepQ62),62位小数,cos取sinTableQ62[len-1-i]
+var sinTableQ62 = [...]uint64{
+	0x0000000000000000, 0x006487c3f99c01c4, 0x00c90e8fe6f63c23, 0x012d936bbe30efd3,
+	0x0192155f7a3667df, 0x01f693731d1cf00f, 0x025b0caeb28ab9a2, 0x02bf801a5219a86c,
+	0x0323ecbe21bb027c, 0x038851a2581afc59, 0x03ecadcf3f041bfd, 0x0451004d35c26c9f,
+	0x04b54824b3867d72, 0x0519845e49c8256a, 0x057db402a6a9062f, 0x05e1d61a9756c854,
+	0x0645e9af0a6d0af7, 0x06a9edc9125700dd, 0x070de171e7b0b53c, 0x0771c3b2eba7f243,
+	0x07d59395aa5cc38b, 0x08395023dd418e8f, 0x089cf8676d7abb53, 0x09008b6a763de759,
+	0x0964083747309d0f, 0x09c76dd866c689da, 0x0a2abb58949f2ceb, 0x0a8defc2cbe2f8fa,
+	0x0af10a22459fe328, 0x0b5409827b25591c, 0x0bb6ecef285f98a1, 0x0c19b3744e3262da,
+	0x0c7c5c1e34d30558, 0x0cdee5f96e21b32f, 0x0d415012d802284c, 0x0da399779eb39134,
+	0x0e05c1353f27b17b, 0x0e67c6598959430f, 0x0ec9a7f2a2a188ab, 0x0f2b650f080d0da5,
+	0x0f8cfcbd90af8d54, 0x0fee6e0d6ff6fc56, 0x104fb80e37fdadfb, 0x10b0d9cfdbdb900f,
+	0x1111d262b1f6775c, 0x1172a0d776517720, 0x11d3443f4cdb3dcd, 0x1233bbabc3bb7162,
+	0x1294062ed59f05a4, 0x12f422daec03869e, 0x135410c2e18151ac, 0x13b3cefa0414b778,
+	0x14135c941766013e, 0x1472b8a5571053ba, 0x14d1e24278e76a1f, 0x1530d880af3c237c,
+	0x158f9a75ab1fdcf9, 0x15ee27379ea69353, 0x164c7ddd3f27c60b, 0x16aa9d7dc77e16ac,
+	0x17088530fa459ea9, 0x1766340f2418f645, 0x17c3a9311dcce6fc, 0x1820e3b04eaac3ed,
+	0x187de2a6aea962cc, 0x18daa52ec8a4afcb, 0x19372a63bc93d727, 0x1993716141bdfeb4,
+	0x19ef7943a8ed8a27, 0x1a4b4127dea1e48a, 0x1aa6c82b6d3fc984, 0x1b020d6c7f40090d,
+	0x1b5d1009e15cc025, 0x1bb7cf2304bd012d, 0x1c1249d8011ee699, 0x1c6c7f4997000a89,
+	0x1cc66e9931c45e10, 0x1d2016e8e9db5ac0, 0x1d79775b86e3894e, 0x1dd28f1481cc57ea,
+	0x1e2b5d3806f63b17, 0x1e83e0eaf85113c9, 0x1edc1952ef78d581, 0x1f3405963fd0673a,
+	0x1f8ba4dbf89ab9f4, 0x1fe2f64be7120fae, 0x2039f90e987d6dab, 0x2090ac4d5c4434d5,
+	0x20e70f3245ffdb25, 0x213d20e82f8bc0f9, 0x2192e09abb131d31, 0x21e84d76551cfb1a,
+	0x223d66a836964500, 0x22922b5e66d9d675, 0x22e69ac7bdb69138, 0x233ab413e5736fd1,
+	0x238e76735cd190d1, 0x23e1e117790c35d6, 0x2434f33267d6b15a, 0x2487abf731583e69,
+	0x24da0a99ba25bd49, 0x252c0e4ec539504d, 0x257db64bf5e7d3e6, 0x25cf01c7d1d42d1f,
+	0x261feff9c2e069b9, 0x2670801a191cad21, 0x26c0b1620cb3e567, 0x2710830bbfd6438f,
+	0x275ff45240a17270, 0x27af04718b068776, 0x27fdb2a68aada892, 0x284bfe2f1cd762b5,
+	0x2899e64a123bac27, 0x28e76a3730e68e30, 0x2934893736127163, 0x2981428bd8000809,
+	0x29cd9577c7cbd21e, 0x2a19813eb3413651, 0x2a65052546ab2b8f, 0x2ab020712ea26e9a,
+	0x2afad26919d93f3c, 0x2b451a54bae4a0a3, 0x2b8ef77cca03187a, 0x2bd8692b06e0e86e,
+	0x2c216eaa3a59bdad, 0x2c6a07463837d219, 0x2cb2324be0f07ad9, 0x2cf9ef09235e2002,
+	0x2d413cccfe779918, 0x2d881ae78304ea1b, 0x2dce88a9d5515d09, 0x2e1485662edaf380,
+	0x2e5a106fdfff2c7d, 0x2e9f291b51a519f7, 0x2ee3cebe06e4c24e, 0x2f2800ae9eabc971,
+	0x2f6bbe44d55f5db3, 0x2faf06d9867b643c, 0x2ff1d9c6ae2ee128, 0x303436676af59748,
+	0x30761c17ff2edb9b, 0x30b78a35d2b19899, 0x30f8801f745d7d60, 0x3138fd349ba954e4,
+	0x317900d62a2e8160, 0x31b88a662d31981a, 0x31f79947df2819c9, 0x32362cdfa93b43cf,
+	0x3274449324c7f695, 0x32b1dfc91cdbad4c, 0x32eefde98fae836c, 0x332b9e5db01a4455,
+	0x3367c08fe70e815e, 0x33a363ebd501aada, 0x33de87de535f2863, 0x34192bd575f26d06,
+	0x34534f408c4f03b2, 0x348cf19023359084, 0x34c6123605f5c37d, 0x34feb0a53fcd392b,
+	0x3536cc521d4345fd, 0x356e64b22d81a8cb, 0x35a5793c43aa2153, 0x35dc09687828e75a,
+	0x361214b02a03ff2e, 0x36479a8e0027684e, 0x367c9a7deaae2301, 0x36b113fd242809bb,
+	0x36e5068a32dc7b19, 0x371871a4ea09d16d, 0x374b54ce6b21a4b6, 0x377daf892701d405,
+	0x37af8158df2a5336, 0x37e0c9c2a6efba1b, 0x3811884ce4aa9212, 0x3841bc7f52e35f1d,
+	0x387165e3017b619c, 0x38a0840256d20dcc, 0x38cf166910e73633, 0x38fd1ca44679e62e,
+	0x392a96426823e9e5, 0x395782d3417200da, 0x3983e1e7f9f8b871, 0x39afb3131665ebb9,
+	0x39daf5e8798ee5d9, 0x3a05a9fd657b2485, 0x3a2fcee87c6bb7e7, 0x3a596441c1df3d7c,
+	0x3a8269a29b927351, 0x3aaadea5d27d6138, 0x3ad2c2e793cd157e, 0x3afa160571d9f2b8,
+	0x3b20d79e651a8c4a, 0x3b470752cd130f4c, 0x3b6ca4c47141358d, 0x3b91af968204c054,
+	0x3bb6276d998478bb, 0x3bda0befbc8fb362, 0x3bfd5cc45b7c5550, 0x3c201994530157d9,
+	0x3c424209ed0dc978, 0x3c63d5d0e19c498a, 0x3c84d4965782fccd, 0x3ca53e08e53ff8c2,
+	0x3cc511d891c223d7, 0x3ce44fb6d52e888a, 0x3d02f75699a21985, 0x3d21086c3befe4e1,
+	0x3d3e82ad8c5bb4b5, 0x3d5b65d1cf511b30, 0x3d77b191be16e86c, 0x3d9365a7877f0840,
+	0x3dae81ced092c674, 0x3dc905c4b53b778d, 0x3de2f147c8e784ac, 0x3dfc4418172bd8df,
+	0x3e14fdf72461ae4f, 0x3e2d1ea7ee40b9d6, 0x3e44a5eeec75b36a, 0x3e5b939211353a06,
+	0x3e71e758c9cb1185, 0x3e87a10bff25b933, 0x3e9cc076165e5998, 0x3eb14562f13d0843,
+	0x3ec52f9feeb96051, 0x3ed87efbeb776e5d, 0x3eeb33474240eebe, 0x3efd4c53cc7adcd9,
+	0x3f0ec9f4e2975267, 0x3f1fabff5c83b599, 0x3f2ff2499213350b, 0x3f3f9cab5b659079,
+	0x3f4eaafe114a2d3f, 0x3f5d1d1c8d9f75ae, 0x3f6af2e32bae8244, 0x3f782c2fc8830bf2,
+	0x3f84c8e1c33fa68c, 0x3f90c8d9fd6e4296, 0x3f9c2bfadb4cf5a6, 0x3fa6f228441708a7,
+	0x3fb11b47a24a4b3a, 0x3fbaa73fe3e8ab93, 0x3fc395f97ab61232, 0x3fcbe75e5c7280d7,
+	0x3fd39b5a03107428, 0x3fdab1d96ce78785, 0x3fe12acb1ce35a7f, 0x3fe7061f1aaeb799,
+	0x3fec43c6f2dafbc6, 0x3ff0e3b5b703be62, 0x3ff4e5dffdeeb939, 0x3ff84a3be3a7f05e,
+	0x3ffb10c1099a1976, 0x3ffd396896a34256, 0x3ffec42d3725b6af, 0x3fffb10b1d15249b,
+	0x4000000000000000,
+}
@@ -0,0 +1,290 @@
+package fixrand
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+
+	"github.com/deminzhang/go-common/fix64"
+	"github.com/deminzhang/go-common/geom2d"
+	"github.com/deminzhang/go-common/vec"
+)
+
+// 确定性随机数,用于帧同步
+// 算法为xoshiro256**,种子经splitmix64展开,不依赖math/rand,各平台各Go版本序列一致
+// 状态可快照和恢复,回滚时:
+//
+//	s := rng.State()
+//	...
+//	rng.SetState(s)
+
+// State 生成器的完整状态
+type State [4]uint64
+
+// Rand 非并发安全
+type Rand struct {
+	s State
+}
+
+func New(seed uint64) *Rand {
+	r := &Rand{}
+	r.Seed(seed)
+	return r
+}
+
+// Seed 以seed重置状态
+func (r *Rand) Seed(seed uint64) {
+	for i := range r.s {
+		seed += 0x9e3779b97f4a7c15
+		z := seed
+		z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+		z = (z ^ z>>27) * 0x94d049bb133111eb
+		r.s[i] = z ^ z>>31
+	}
+}
+
+// State 状态快照
+func (r *Rand) State() State {
+	return r.s
+}
+
+// SetState 恢复到快照
+func (r *Rand) SetState(s State) {
+	r.s = s
+}
+
+// MarshalBinary 32字节大端状态
+func (r *Rand) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, 32)
+	for _, v := range r.s {
+		b = binary.BigEndian.AppendUint64(b, v)
+	}
+	return b, nil
+}
+
+func (r *Rand) UnmarshalBinary(data []byte) error {
+	if len(data) != 32 {
+		return fmt.Errorf("fixrand: UnmarshalBinary: want 32 bytes, got %d", len(data))
+	}
+	for i := range r.s {
+		r.s[i] = binary.BigEndian.Uint64(data[i*8:])
+	}
+	return nil
+}
+
+func (r *Rand) Uint64() uint64 {
+	s := &r.s
+	ret := bits.RotateLeft64(s[1]*5, 7) * 9
+	t := s[1] << 17
+	s[2] ^= s[0]
+	s[3] ^= s[1]
+	s[1] ^= s[2]
+	s[0] ^= s[3]
+	s[2] ^= t
+	s[3] = bits.RotateLeft64(s[3], 45)
+	return ret
+}
+
+func (r *Rand) Uint32() uint32 {
+	return uint32(r.Uint64() >> 32)
+}
+
+// Uint64n [0,n)均匀分布,n为0时panic
+func (r *Rand) Uint64n(n uint64) uint64 {
+	if n == 0 {
+		panic("fixrand: invalid argument to Uint64n")
+	}
+	hi, lo := bits.Mul64(r.Uint64(), n)
+	if lo < n {
+		thresh := -n % n
+		for lo < thresh {
+			hi, lo = bits.Mul64(r.Uint64(), n)
+		}
+	}
+	return hi
+}
+
+// Intn [0,n),n<=0时panic
+func (r *Rand) Intn(n int) int {
+	if n <= 0 {
+		panic("fixrand: invalid argument to Intn")
+	}
+	return int(r.Uint64n(uint64(n)))
+}
+
+// RangeInt [lo,hi]
+func (r *Rand) RangeInt(lo, hi int) int {
+	if hi <= lo {
+		return lo
+	}
+	// hi-lo可能超出int,按uint取差;区间为整个int64时span回绕为0,直接取随机数
+	span := uint64(uint(hi-lo)) + 1
+	if span == 0 {
+		return lo + int(r.Uint64())
+	}
+	return lo + int(r.Uint64n(span))
+}
+
+func (r *Rand) Bool() bool {
+	return r.Uint64()>>63 == 1
+}
+
+// Fix64 [0,1)
+func (r *Rand) Fix64() fix64.Fix64 {
+	return fix64.Fix64(r.Uint64() >> 32)
+}
+
+// Range [lo,hi),hi<=lo时返回lo
+func (r *Rand) Range(lo, hi fix64.Fix64) fix64.Fix64 {
+	if hi <= lo {
+		return lo
+	}
+	return lo + fix64.Fix64(r.Uint64n(uint64(hi-lo)))
+}
+
+// Chance 以概率p返回true,p为[0,1]
+func (r *Rand) Chance(p fix64.Fix64) bool {
+	return r.Fix64() < p
+}
+
+// Weighted 按权重选下标,权重<=0的项不会被选中,总权重<=0时返回-1
+// 总权重超出uint64时所有权重右移到不溢出,比例误差小于2^-60
+func (r *Rand) Weighted(weights []fix64.Fix64) int {
+	var hi, total uint64
+	for _, w := range weights {
+		if w > 0 {
+			var carry uint64
+			total, carry = bits.Add64(total, uint64(w), 0)
+			hi += carry
+		}
+	}
+	shift := uint(bits.Len64(hi))
+	if shift > 0 {
+		total = 0
+		for _, w := range weights {
+			if w > 0 {
+				total += uint64(w) >> shift
+			}
+		}
+	}
+	if total == 0 {
+		return -1
+	}
+	n := r.Uint64n(total)
+	for i, w := range weights {
+		if w <= 0 {
+			continue
+		}
+		uw := uint64(w) >> shift
+		if n < uw {
+			return i
+		}
+		n -= uw
+	}
+	return -1 //不会到这里
+}
+
+// Shuffle Fisher-Yates洗牌
+func (r *Rand) Shuffle(n int, swap func(i, j int)) {
+	for i := n - 1; i > 0; i-- {
+		swap(i, r.Intn(i+1))
+	}
+}
+
+// Perm [0,n)的随机排列
+func (r *Rand) Perm(n int) []int {
+	p := make([]int, n)
+	for i := range p {
+		p[i] = i
+	}
+	r.Shuffle(n, func(i, j int) { p[i], p[j] = p[j], p[i] })
+	return p
+}
+
+// Choice 随机选一个元素,s为空时返回零值
+func Choice[T any](r *Rand, s []T) T {
+	if len(s) == 0 {
+		var zero T
+		return zero
+	}
+	return s[r.Intn(len(s))]
+}
+
+// ShuffleSlice 原地洗牌
+func ShuffleSlice[T any](r *Rand, s []T) {
+	r.Shuffle(len(s), func(i, j int) { s[i], s[j] = s[j], s[i] })
+}
+
+// inUnitDisk 单位圆内均匀分布的点(拒绝采样)
+func (r *Rand) inUnitDisk() vec.FixVector2 {
+	for {
+		v := vec.FixVector2{X: r.Range(-fix64.FixOne, fix64.FixOne), Y: r.Range(-fix64.FixOne, fix64.FixOne)}
+		if v.SqrMagnitude() <= fix64.FixOne {
+			return v
+		}
+	}
+}
+
+// UnitVector 方向均匀分布的单位向量
+func (r *Rand) UnitVector() vec.FixVector2 {
+	for {
+		v := r.inUnitDisk()
+		if v.SqrMagnitude() > fix64.FixOne>>8 { //太短时方向精度差,重取
+			v.Normalize()
+			return v
+		}
+	}
+}
+
+// InCircle 圆内均匀分布的点
+func (r *Rand) InCircle(center vec.FixVector2, radius fix64.Fix64) vec.FixVector2 {
+	v := r.inUnitDisk()
+	v.Multiply(radius)
+	return vec.AddFV2(center, v)
+}
+
+// PointIn 图形内均匀分布的点,不支持的图形返回零向量
+func (r *Rand) PointIn(shape geom2d.IFixShape) vec.FixVector2 {
+	switch s := shape.(type) {
+	case *geom2d.FixPoint:
+		return s.Pos
+	case *geom2d.FixLineSegment:
+		return vec.LerpFV2(s.P1.Pos, s.P2.Pos, r.Fix64())
+	case *geom2d.FixCircle:
+		return r.InCircle(s.Pos, s.Radius)
+	case *geom2d.FixSector:
+		// 按极坐标取点: 角度在张角内均匀,半径取R*sqrt(u);舍入到边界外时重取,几次都不行时返回圆心
+		// 张角为0时扇形只有起始半径,取到的点舍入后一般不在半径上,返回圆心
+		fix360 := fix64.FromInt(360)
+		span := (s.EndAngle - s.StartAngle) % fix360
+		if span < 0 {
+			span += fix360
+		}
+		for i := 0; i < 8 && span != 0 && s.Radius > 0; i++ {
+			sin, cos := (s.StartAngle + span.Mul(r.Fix64())).SinCosDeg()
+			p := vec.FixVector2{X: cos, Y: sin}
+			p.Multiply(s.Radius.Mul(r.Fix64().Sqrt()))
+			p = vec.AddFV2(s.Pos, p)
+			if s.Intersects(geom2d.NewFixPoint(p.X, p.Y)) {
+				return p
+			}
+		}
+		return s.Pos
+	case *geom2d.FixAABB:
+		return r.PointIn(&geom2d.FixOBB{FixAABB: *s})
+	case *geom2d.FixOBB:
+		c := s.Corners()
+		p := vec.LerpFV2(c[2], c[3], r.Fix64())
+		p.Add(vec.SubFV2(vec.LerpFV2(c[2], c[1], r.Fix64()), c[2]))
+		return p
+	case *geom2d.FixTriangle:
+		u, v := r.Fix64(), r.Fix64()
+		if u+v > fix64.FixOne { // 翻折到三角形内
+			u, v = fix64.FixOne-u, fix64.FixOne-v
+		}
+		p := vec.LerpFV2(s.A.Pos, s.B.Pos, u)
+		p.Add(vec.SubFV2(vec.LerpFV2(s.A.Pos, s.C.Pos, v), s.A.Pos))
+		return p
+	}
+	return vec.FixVector2{}
+}
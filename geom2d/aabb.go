@@ -29,6 +29,10 @@ func (a *AABB) Intersects(target IShape) bool {
 		return rectRectIntersectSAT(&OBB{AABB: *a}, &OBB{AABB: *other})
 	case *OBB:
 		return rectRectIntersectSAT(&OBB{AABB: *a}, other)
+	case *Sector:
+		return (&OBB{AABB: *a}).Intersects(other)
+	case *Polygon:
+		return other.Intersects(a)
 	}
 	return false
 }
@@ -45,3 +49,21 @@ func segmentRectIntersectSAT(ls *LineSegment, a *AABB) bool {
 func triangleRectIntersectSAT(t *Triangle, a *AABB) bool {
 	return (&OBB{AABB: *a}).Intersects(t)
 }
+
+func (a *AABB) box() box { return aabbBox(a) }
+
+func (a *AABB) Bounds() AABB { return *a }
+
+func (a *AABB) Area() float32 { return a.Width * a.Height }
+
+func (a *AABB) Centroid() vec.Vec2[float32] { return a.Pos }
+
+func (a *AABB) Contains(p vec.Vec2[float32]) bool {
+	b := a.box()
+	return b.minX <= p.X && p.X <= b.maxX && b.minY <= p.Y && p.Y <= b.maxY
+}
+
+func (a *AABB) ClosestPoint(p vec.Vec2[float32]) vec.Vec2[float32] {
+	b := a.box()
+	return vec.Vec2[float32]{X: clampf(p.X, b.minX, b.maxX), Y: clampf(p.Y, b.minY, b.maxY)}
+}
@@ -0,0 +1,229 @@
+package geom2d
+
+// AABBTree 动态包围盒树,叶子的包围盒外扩margin,形状在外扩范围内移动时不用调整树
+// 插入时按周长启发式选兄弟节点,并做AVL旋转保持平衡;适合大量运动、大小不一的形状
+type AABBTree struct {
+	root   *treeNode
+	margin float32
+	leaves map[IShape]*treeNode
+	nextID uint64
+}
+
+type treeNode struct {
+	box                 box //叶子为外扩后的包围盒,内部节点为子节点的并
+	parent, left, right *treeNode
+	height              int //叶子为0
+	// 以下只有叶子有
+	shape IShape
+	tight box
+	id    uint64
+}
+
+func (n *treeNode) leaf() bool { return n.left == nil }
+
+var _ BroadPhase = (*AABBTree)(nil)
+
+// NewAABBTree margin一般取形状每帧移动距离的几倍
+func NewAABBTree(margin float32) *AABBTree {
+	return &AABBTree{margin: margin, leaves: map[IShape]*treeNode{}}
+}
+
+func (t *AABBTree) Len() int { return len(t.leaves) }
+
+func (t *AABBTree) Insert(s IShape) bool {
+	if _, ok := t.leaves[s]; ok {
+		return false
+	}
+	b := shapeBox(s)
+	leaf := &treeNode{box: b.expand(t.margin), shape: s, tight: b, id: t.nextID}
+	t.nextID++
+	t.leaves[s] = leaf
+	t.insertLeaf(leaf)
+	return true
+}
+
+func (t *AABBTree) Move(s IShape) bool {
+	leaf, ok := t.leaves[s]
+	if !ok {
+		return false
+	}
+	leaf.tight = shapeBox(s)
+	if leaf.box.contains(leaf.tight) {
+		return true
+	}
+	t.removeLeaf(leaf)
+	leaf.box = leaf.tight.expand(t.margin)
+	t.insertLeaf(leaf)
+	return true
+}
+
+func (t *AABBTree) Remove(s IShape) bool {
+	leaf, ok := t.leaves[s]
+	if !ok {
+		return false
+	}
+	t.removeLeaf(leaf)
+	delete(t.leaves, s)
+	return true
+}
+
+func (t *AABBTree) insertLeaf(leaf *treeNode) {
+	if t.root == nil {
+		t.root = leaf
+		leaf.parent = nil
+		return
+	}
+	// 找代价最小的兄弟节点:新建父节点的周长加上祖先增加的周长
+	lb := leaf.box
+	n := t.root
+	for !n.leaf() {
+		combined := n.box.union(lb).perimeter()
+		cost := 2 * combined
+		inherit := 2 * (combined - n.box.perimeter())
+		childCost := func(c *treeNode) float32 {
+			if c.leaf() {
+				return c.box.union(lb).perimeter() + inherit
+			}
+			return c.box.union(lb).perimeter() - c.box.perimeter() + inherit
+		}
+		costL, costR := childCost(n.left), childCost(n.right)
+		if cost < costL && cost < costR {
+			break
+		}
+		if costL < costR {
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+	oldParent := n.parent
+	p := &treeNode{box: n.box.union(lb), parent: oldParent, left: n, right: leaf, height: n.height + 1}
+	n.parent, leaf.parent = p, p
+	t.replaceChild(oldParent, n, p)
+	t.refit(p)
+}
+
+func (t *AABBTree) removeLeaf(leaf *treeNode) {
+	if leaf == t.root {
+		t.root = nil
+		return
+	}
+	p := leaf.parent
+	sibling := p.left
+	if sibling == leaf {
+		sibling = p.right
+	}
+	t.replaceChild(p.parent, p, sibling)
+	t.refit(sibling.parent)
+}
+
+// replaceChild 把parent的子节点old换成n,parent为nil时n成为根
+func (t *AABBTree) replaceChild(parent, old, n *treeNode) {
+	n.parent = parent
+	switch {
+	case parent == nil:
+		t.root = n
+	case parent.left == old:
+		parent.left = n
+	default:
+		parent.right = n
+	}
+}
+
+// refit 从n向上平衡并更新包围盒和高度
+func (t *AABBTree) refit(n *treeNode) {
+	for n != nil {
+		n = t.balance(n)
+		n.height = 1 + max(n.left.height, n.right.height)
+		n.box = n.left.box.union(n.right.box)
+		n = n.parent
+	}
+}
+
+// balance 左右子树高度差大于1时把较高的子节点旋转上来,返回旋转后子树的根
+func (t *AABBTree) balance(a *treeNode) *treeNode {
+	if a.leaf() || a.height < 2 {
+		return a
+	}
+	b, c := a.left, a.right
+	switch diff := c.height - b.height; {
+	case diff > 1:
+		t.rotateUp(a, c, b, true)
+		return c
+	case diff < -1:
+		t.rotateUp(a, b, c, false)
+		return b
+	}
+	return a
+}
+
+// rotateUp 把a的子节点up提为a的父节点,other为a的另一个子节点;up较高的孙节点留在up下,较矮的给a
+func (t *AABBTree) rotateUp(a, up, other *treeNode, upIsRight bool) {
+	f, g := up.left, up.right
+	t.replaceChild(a.parent, a, up)
+	up.left = a
+	a.parent = up
+	if f.height < g.height {
+		f, g = g, f
+	}
+	// f较高,g较矮
+	up.right = f
+	if upIsRight {
+		a.right = g
+	} else {
+		a.left = g
+	}
+	g.parent = a
+	a.box = other.box.union(g.box)
+	a.height = 1 + max(other.height, g.height)
+	up.box = a.box.union(f.box)
+	up.height = 1 + max(a.height, f.height)
+}
+
+func (t *AABBTree) query(b box, match func(box) bool, visit func(*treeNode)) {
+	if t.root == nil {
+		return
+	}
+	stack := []*treeNode{t.root}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !n.box.overlaps(b) {
+			continue
+		}
+		if n.leaf() {
+			if match(n.tight) {
+				visit(n)
+			}
+			continue
+		}
+		stack = append(stack, n.left, n.right)
+	}
+}
+
+func (t *AABBTree) QueryAABB(region *AABB) []IShape {
+	var res []IShape
+	b := aabbBox(region)
+	t.query(b, b.overlaps, func(n *treeNode) { res = append(res, n.shape) })
+	return res
+}
+
+func (t *AABBTree) QueryCircle(x, y, r float32) []IShape {
+	var res []IShape
+	t.query(box{x - r, y - r, x + r, y + r}, func(b box) bool { return b.overlapsCircle(x, y, r) },
+		func(n *treeNode) { res = append(res, n.shape) })
+	return res
+}
+
+// Pairs 每个叶子查询一次,只计入比它后加入的形状
+func (t *AABBTree) Pairs() [][2]IShape {
+	var res []candidatePair
+	for _, leaf := range t.leaves {
+		t.query(leaf.tight, leaf.tight.overlaps, func(n *treeNode) {
+			if n.id > leaf.id {
+				res = append(res, candidatePair{[2]uint64{leaf.id, n.id}, [2]IShape{leaf.shape, n.shape}})
+			}
+		})
+	}
+	return sortPairs(res)
+}
@@ -0,0 +1,117 @@
+package geom2d
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/deminzhang/go-common/vec"
+)
+
+// BroadPhase 粗检测的空间索引,只比较包围盒,结果为候选,需再用Intersects或Collide精确判定
+// 形状以指针为键;形状移动或改变大小后需调用Move更新索引;非并发安全
+type BroadPhase interface {
+	// Insert 加入形状,已存在时返回false
+	Insert(s IShape) bool
+	// Move 形状移动或改变大小后更新索引,不存在时返回false
+	Move(s IShape) bool
+	// Remove 移除形状,不存在时返回false
+	Remove(s IShape) bool
+	// QueryAABB 包围盒与区域重叠的形状
+	QueryAABB(region *AABB) []IShape
+	// QueryCircle 包围盒与圆重叠的形状
+	QueryCircle(x, y, r float32) []IShape
+	// Pairs 包围盒重叠的形状对,每对只出现一次,先加入的在前,按加入顺序排序
+	Pairs() [][2]IShape
+	Len() int
+}
+
+// box 轴对齐包围盒,min/max表示
+type box struct {
+	minX, minY, maxX, maxY float32
+}
+
+func (b box) overlaps(o box) bool {
+	return b.minX <= o.maxX && o.minX <= b.maxX && b.minY <= o.maxY && o.minY <= b.maxY
+}
+
+func (b box) contains(o box) bool {
+	return b.minX <= o.minX && b.minY <= o.minY && o.maxX <= b.maxX && o.maxY <= b.maxY
+}
+
+func (b box) union(o box) box {
+	return box{min(b.minX, o.minX), min(b.minY, o.minY), max(b.maxX, o.maxX), max(b.maxY, o.maxY)}
+}
+
+func (b box) expand(d float32) box {
+	return box{b.minX - d, b.minY - d, b.maxX + d, b.maxY + d}
+}
+
+// perimeter 周长,二维的表面积启发式用
+func (b box) perimeter() float32 {
+	return 2 * (b.maxX - b.minX + b.maxY - b.minY)
+}
+
+// overlapsCircle 包围盒与圆是否重叠
+func (b box) overlapsCircle(x, y, r float32) bool {
+	dx := float64(x - clampf(x, b.minX, b.maxX))
+	dy := float64(y - clampf(y, b.minY, b.maxY))
+	return dx*dx+dy*dy <= float64(r)*float64(r)
+}
+
+func (b box) aabb() AABB {
+	return AABB{BaseShape: BaseShape{Pos: vec.Vec2[float32]{X: (b.minX + b.maxX) / 2, Y: (b.minY + b.maxY) / 2}}, Width: b.maxX - b.minX, Height: b.maxY - b.minY}
+}
+
+func clampf(v, lo, hi float32) float32 {
+	return min(max(v, lo), hi)
+}
+
+func pointsBox(pts ...vec.Vec2[float32]) box {
+	b := box{pts[0].X, pts[0].Y, pts[0].X, pts[0].Y}
+	for _, p := range pts[1:] {
+		b = b.union(box{p.X, p.Y, p.X, p.Y})
+	}
+	return b
+}
+
+func aabbBox(a *AABB) box {
+	hw, hh := a.Width/2, a.Height/2
+	return box{a.Pos.X - hw, a.Pos.Y - hh, a.Pos.X + hw, a.Pos.Y + hh}
+}
+
+// shapeBox 形状的包围盒,优先用min/max表示的精确值,避免经中心和宽高转换的误差
+func shapeBox(s IShape) box {
+	if b, ok := s.(interface{ box() box }); ok {
+		return b.box()
+	}
+	b := s.Bounds()
+	return aabbBox(&b)
+}
+
+// candidatePair 形状对,ids[0]<ids[1]
+type candidatePair struct {
+	ids    [2]uint64
+	shapes [2]IShape
+}
+
+func newPair(a, b IShape, idA, idB uint64) candidatePair {
+	if idA < idB {
+		return candidatePair{[2]uint64{idA, idB}, [2]IShape{a, b}}
+	}
+	return candidatePair{[2]uint64{idB, idA}, [2]IShape{b, a}}
+}
+
+// sortPairs 按加入顺序排序,使结果与遍历顺序无关
+func sortPairs(ps []candidatePair) [][2]IShape {
+	slices.SortFunc(ps, func(a, b candidatePair) int {
+		if c := cmp.Compare(a.ids[0], b.ids[0]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ids[1], b.ids[1])
+	})
+	res := make([][2]IShape, len(ps))
+	for i, p := range ps {
+		res[i] = p.shapes
+	}
+	return res
+}
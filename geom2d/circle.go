@@ -40,6 +40,8 @@ func (c *Circle) Intersects(target IShape) bool {
 		return other.Intersects(c)
 	case *Triangle:
 		return other.Intersects(c)
+	case *Polygon:
+		return other.Intersects(c)
 	}
 	return false
 }
@@ -85,3 +87,23 @@ func (c *Circle) intersectsSector(sector *Sector) bool {
 	}
 	return false
 }
+
+func (c *Circle) box() box { return pointsBox(c.Pos).expand(c.Radius) }
+
+func (c *Circle) Bounds() AABB { return c.box().aabb() }
+
+func (c *Circle) Area() float32 { return math.Pi * c.Radius * c.Radius }
+
+func (c *Circle) Centroid() vec.Vec2[float32] { return c.Pos }
+
+func (c *Circle) Contains(p vec.Vec2[float32]) bool {
+	dx, dy := float64(p.X-c.Pos.X), float64(p.Y-c.Pos.Y)
+	return dx*dx+dy*dy <= float64(c.Radius)*float64(c.Radius)
+}
+
+func (c *Circle) ClosestPoint(p vec.Vec2[float32]) vec.Vec2[float32] {
+	if c.Contains(p) {
+		return p
+	}
+	return pointOnCircle(c.Pos, c.Radius, p)
+}
@@ -0,0 +1,406 @@
+package geom2d
+
+import (
+	"math"
+	"slices"
+
+	"github.com/deminzhang/go-common/vec"
+)
+
+// Contact 两个形状的碰撞信息
+type Contact struct {
+	Normal vec.Vec2[float32]   // 单位法线,由a指向b
+	Depth  float32             // 穿透深度,b沿Normal移动Depth(或a反向移动)即可分离,相切时为0
+	Points []vec.Vec2[float32] // 接触点,1或2个
+}
+
+// collider 凸形状的统一表示: 多边形(可退化为1个点或线段)加可选的圆或圆弧
+type collider struct {
+	verts  []vec.Vec2[float32] // 多边形顶点;圆为圆心,扇形为圆心和两条半径的外端点
+	axes   []vec.Vec2[float32] // 边的法线等分离轴候选
+	radius float32             // 圆或扇形的半径
+	sector *Sector             // 非nil时圆弧只在扇形的角度范围内
+	inner  []vec.Vec2[float32] // 拆分非凸形状产生的内部边的法线,不是真实的表面,不作为分离方向
+}
+
+// round 是否有圆形部分,圆心为verts[0];点视为半径0的圆
+func (c *collider) round() bool {
+	return len(c.verts) == 1 || c.sector != nil
+}
+
+func unitVec(x, y float64) (vec.Vec2[float32], bool) {
+	l := math.Hypot(x, y)
+	if l == 0 {
+		return vec.Vec2[float32]{}, false
+	}
+	return vec.Vec2[float32]{X: float32(x / l), Y: float32(y / l)}, true
+}
+
+// edgeNormal 边ab的单位法线
+func edgeNormal(a, b vec.Vec2[float32]) (vec.Vec2[float32], bool) {
+	return unitVec(-float64(b.Y-a.Y), float64(b.X-a.X))
+}
+
+func polygonCollider(pts []vec.Vec2[float32]) *collider {
+	c := &collider{verts: pts}
+	for i, a := range pts {
+		if n, ok := edgeNormal(a, pts[(i+1)%len(pts)]); ok {
+			c.axes = append(c.axes, n)
+		}
+	}
+	return c
+}
+
+func sectorCollider(s *Sector) *collider {
+	start, end := sectorEnds(s)
+	c := &collider{verts: []vec.Vec2[float32]{s.Pos, start, end}, radius: s.Radius, sector: s}
+	for _, p := range []vec.Vec2[float32]{start, end} {
+		if n, ok := edgeNormal(s.Pos, p); ok {
+			c.axes = append(c.axes, n)
+		}
+	}
+	return c
+}
+
+func toCollider(s IShape) (*collider, bool) {
+	switch v := s.(type) {
+	case *Point:
+		return &collider{verts: []vec.Vec2[float32]{v.Pos}}, true
+	case *Circle:
+		return &collider{verts: []vec.Vec2[float32]{v.Pos}, radius: v.Radius}, true
+	case *AABB:
+		r := &OBB{AABB: *v}
+		return &collider{verts: rectangleCorners(r), axes: rectAxes(r)}, true
+	case *OBB:
+		return &collider{verts: rectangleCorners(v), axes: rectAxes(v)}, true
+	case *Triangle:
+		return polygonCollider([]vec.Vec2[float32]{v.A.Pos, v.B.Pos, v.C.Pos}), true
+	case *LineSegment:
+		c := polygonCollider([]vec.Vec2[float32]{v.P1.Pos, v.P2.Pos})
+		// 共线的两条线段只能沿线段方向分离
+		if d, ok := unitVec(float64(v.P2.Pos.X-v.P1.Pos.X), float64(v.P2.Pos.Y-v.P1.Pos.Y)); ok {
+			c.axes = append(c.axes[:1], d)
+		}
+		return c, true
+	case *Sector:
+		return sectorCollider(v), true
+	}
+	return nil, false
+}
+
+// arcContains 方向d是否在扇形圆弧的角度范围内
+func (c *collider) arcContains(d vec.Vec2[float32]) bool {
+	s := c.sector
+	return angleInSector(vec.Vec2[float32]{X: s.Pos.X + d.X, Y: s.Pos.Y + d.Y}, s)
+}
+
+// project 在单位轴上的投影区间
+func (c *collider) project(axis vec.Vec2[float32]) (float64, float64) {
+	lo, hi := projectPointsAxis(c.verts, axis)
+	if c.sector == nil {
+		return lo - float64(c.radius), hi + float64(c.radius)
+	}
+	center := dot(c.verts[0], axis)
+	if c.arcContains(axis) {
+		hi = math.Max(hi, center+float64(c.radius))
+	}
+	if c.arcContains(vec.Vec2[float32]{X: -axis.X, Y: -axis.Y}) {
+		lo = math.Min(lo, center-float64(c.radius))
+	}
+	return lo, hi
+}
+
+// feature 在方向n上最远的点,1个点或共线的2个端点(一条边)
+func (c *collider) feature(n vec.Vec2[float32]) []vec.Vec2[float32] {
+	cands := c.verts
+	if c.radius > 0 && (c.sector == nil || c.arcContains(n)) {
+		arc := vec.Vec2[float32]{X: c.verts[0].X + n.X*c.radius, Y: c.verts[0].Y + n.Y*c.radius}
+		if c.sector == nil {
+			return []vec.Vec2[float32]{arc}
+		}
+		cands = append([]vec.Vec2[float32]{arc}, cands...)
+	}
+	_, hi := projectPointsAxis(cands, n)
+	eps := 1e-4 * math.Max(1, math.Abs(hi))
+	var res []vec.Vec2[float32]
+	for _, p := range cands {
+		if dot(p, n) >= hi-eps {
+			res = append(res, p)
+		}
+	}
+	if len(res) <= 2 {
+		return res
+	}
+	// 多于2个时取沿边方向的两端
+	t := vec.Vec2[float32]{X: -n.Y, Y: n.X}
+	first, last := res[0], res[0]
+	for _, p := range res[1:] {
+		if dot(p, t) < dot(first, t) {
+			first = p
+		}
+		if dot(p, t) > dot(last, t) {
+			last = p
+		}
+	}
+	return []vec.Vec2[float32]{first, last}
+}
+
+// candidateAxes 分离轴候选: 双方的边法线和圆心到对方顶点的方向;skipInner时去掉内部边的法线
+func candidateAxes(a, b *collider, skipInner bool) []vec.Vec2[float32] {
+	var axes []vec.Vec2[float32]
+	for _, c := range [2]*collider{a, b} {
+		for _, n := range c.axes {
+			if !skipInner || !slices.Contains(c.inner, n) {
+				axes = append(axes, n)
+			}
+		}
+	}
+	for _, pair := range [2][2]*collider{{a, b}, {b, a}} {
+		if !pair[0].round() {
+			continue
+		}
+		center := pair[0].verts[0]
+		for _, v := range pair[1].verts {
+			if d, ok := unitVec(float64(v.X-center.X), float64(v.Y-center.Y)); ok {
+				axes = append(axes, d)
+			}
+		}
+	}
+	return axes
+}
+
+// collideConvex 分离轴定理求最小穿透
+func collideConvex(a, b *collider) (Contact, bool) {
+	axes := candidateAxes(a, b, false)
+	if len(axes) == 0 { //同心的圆和点
+		axes = append(axes, vec.Vec2[float32]{X: 1})
+	}
+	best := math.MaxFloat64
+	var normal vec.Vec2[float32]
+	for _, axis := range axes {
+		minA, maxA := a.project(axis)
+		minB, maxB := b.project(axis)
+		if !projectionsOverlap(minA, maxA, minB, maxB) {
+			return Contact{}, false
+		}
+		// b在轴正方向时沿axis推开,否则反向
+		if d := maxA - minB; d < best {
+			best, normal = d, axis
+		}
+		if d := maxB - minA; d < best {
+			best, normal = d, vec.Vec2[float32]{X: -axis.X, Y: -axis.Y}
+		}
+	}
+	return Contact{Normal: normal, Depth: float32(best), Points: contactPoints(a, b, normal)}, true
+}
+
+// contactPoints 一方为顶点时取该点(双方都是顶点时取中点),双方都是边时取b的边与a的边重叠部分的两端
+func contactPoints(a, b *collider, n vec.Vec2[float32]) []vec.Vec2[float32] {
+	fa := a.feature(n)
+	fb := b.feature(vec.Vec2[float32]{X: -n.X, Y: -n.Y})
+	switch {
+	case len(fa) == 1 && len(fb) == 1:
+		return []vec.Vec2[float32]{{X: (fa[0].X + fb[0].X) / 2, Y: (fa[0].Y + fb[0].Y) / 2}}
+	case len(fb) == 1:
+		return fb
+	case len(fa) == 1:
+		return fa
+	}
+	t := vec.Vec2[float32]{X: -n.Y, Y: n.X}
+	loA, hiA := projectPointsAxis(fa, t)
+	s0, s1 := dot(fb[0], t), dot(fb[1], t)
+	if s0 == s1 { //b的边退化为一点,如长度为0的线段
+		return fb[:1]
+	}
+	lo, hi := math.Max(math.Min(s0, s1), loA), math.Min(math.Max(s0, s1), hiA)
+	at := func(s float64) vec.Vec2[float32] {
+		k := float32((s - s0) / (s1 - s0))
+		return vec.Vec2[float32]{X: fb[0].X + (fb[1].X-fb[0].X)*k, Y: fb[0].Y + (fb[1].Y-fb[0].Y)*k}
+	}
+	if hi-lo <= 1e-6 {
+		return []vec.Vec2[float32]{at((lo + hi) / 2)}
+	}
+	return []vec.Vec2[float32]{at(lo), at(hi)}
+}
+
+// splitSector 超过180度的扇形拆成两个不超过180度的凸扇形
+func splitSector(c *collider) []*collider {
+	s := c.sector
+	if s == nil {
+		return []*collider{c}
+	}
+	span := sectorSpanDeg(s)
+	if span <= 180 {
+		return []*collider{c}
+	}
+	mid := s.StartAngle + float32(span/2)
+	halves := []*collider{
+		sectorCollider(NewSector(s.Pos.X, s.Pos.Y, s.Radius, s.StartAngle, mid)),
+		sectorCollider(NewSector(s.Pos.X, s.Pos.Y, s.Radius, mid, s.EndAngle)),
+	}
+	// 分割线是内部边: 前一半的终边和后一半的起边
+	_, split := sectorEnds(halves[0].sector)
+	if n, ok := edgeNormal(s.Pos, split); ok {
+		for _, h := range halves {
+			h.inner = []vec.Vec2[float32]{n}
+		}
+	}
+	return halves
+}
+
+// moved 平移后的副本
+func (c *collider) moved(d vec.Vec2[float32]) *collider {
+	res := *c
+	res.verts = make([]vec.Vec2[float32], len(c.verts))
+	for i, v := range c.verts {
+		res.verts[i] = vec.Vec2[float32]{X: v.X + d.X, Y: v.Y + d.Y}
+	}
+	if c.sector != nil {
+		s := *c.sector
+		s.Pos = vec.Vec2[float32]{X: s.Pos.X + d.X, Y: s.Pos.Y + d.Y}
+		res.sector = &s
+	}
+	return &res
+}
+
+// shapeColliders 形状的凸块: 超过180度的扇形拆成两半,凹多边形做凸分解
+func shapeColliders(s IShape) ([]*collider, bool) {
+	if pg, ok := s.(*Polygon); ok {
+		return pg.colliders(), true
+	}
+	c, ok := toCollider(s)
+	if !ok {
+		return nil, false
+	}
+	return splitSector(c), true
+}
+
+// Collide 两个形状是否相交,相交时返回法线、穿透深度和接触点,相切视为相交
+// 支持Point,Circle,AABB,OBB,Triangle,LineSegment,Sector,Polygon两两组合;
+// 非凸的形状(超过180度的扇形,凹多边形)拆成凸块,求使所有凸块对都分离的最小移动,拆分产生的内部边不作为法线
+func Collide(a, b IShape) (Contact, bool) {
+	ca, ok := shapeColliders(a)
+	if !ok {
+		return Contact{}, false
+	}
+	cb, ok := shapeColliders(b)
+	if !ok {
+		return Contact{}, false
+	}
+	if len(ca) == 1 && len(cb) == 1 {
+		return collideConvex(ca[0], cb[0])
+	}
+	var hits [][2]*collider
+	for _, pa := range ca {
+		for _, pb := range cb {
+			if convexIntersects(pa, pb) {
+				hits = append(hits, [2]*collider{pa, pb})
+			}
+		}
+	}
+	if len(hits) == 0 {
+		return Contact{}, false
+	}
+	var axes []vec.Vec2[float32]
+	for _, h := range hits {
+		for _, axis := range candidateAxes(h[0], h[1], true) {
+			if !slices.Contains(axes, axis) {
+				axes = append(axes, axis)
+			}
+		}
+	}
+	if len(axes) == 0 {
+		axes = append(axes, vec.Vec2[float32]{X: 1})
+	}
+	best := math.MaxFloat64
+	var normal vec.Vec2[float32]
+	var binding [2]*collider
+	for _, axis := range axes {
+		for _, n := range [2]vec.Vec2[float32]{axis, {X: -axis.X, Y: -axis.Y}} {
+			if d, pair := separation(ca, cb, hits, n, best); d < best {
+				best, normal, binding = d, n, pair
+			}
+		}
+	}
+	return Contact{Normal: normal, Depth: float32(best), Points: contactPoints(binding[0], binding[1], normal)}, true
+}
+
+// exitDist b沿n移动多远之后与a不再相交;lo处两者相交
+// 对每个分离轴m,b移动(maxA-minB)/(m·n)后沿m分离,取最小值;都是多边形时这些轴就是闵可夫斯基差的全部边,结果精确,
+// 带圆形部分时候选轴随位置变化,结果只是上界,再二分;结果不超过floor或已超过limit时不必精确,提前返回
+func exitDist(pair [2]*collider, n vec.Vec2[float32], lo, floor, limit float64) float64 {
+	a, b := pair[0], pair[1]
+	_, maxA := a.project(n)
+	minB, _ := b.project(n)
+	hi := maxA - minB
+	for _, axis := range candidateAxes(a, b, false) {
+		for _, m := range [2]vec.Vec2[float32]{axis, {X: -axis.X, Y: -axis.Y}} {
+			if mn := dot(m, n); mn > 1e-9 {
+				_, maxA := a.project(m)
+				minB, _ := b.project(m)
+				hi = math.Min(hi, (maxA-minB)/mn)
+			}
+		}
+	}
+	if !a.round() && !b.round() || hi <= floor {
+		return hi
+	}
+	intersects := func(t float64) bool {
+		return convexIntersects(a, b.moved(vec.Vec2[float32]{X: n.X * float32(t), Y: n.Y * float32(t)}))
+	}
+	// 沿直线相交的范围是包含lo的区间,floor处不相交说明出口在floor之前
+	if floor > lo {
+		if !intersects(floor) {
+			return floor
+		}
+		lo = floor
+	}
+	for lo < limit && hi-lo > 1e-6*math.Max(1, math.Abs(hi)) {
+		if mid := (lo + hi) / 2; intersects(mid) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	if lo >= limit {
+		return lo
+	}
+	return hi
+}
+
+// separation b沿n移动多远才能与a完全分离,以及决定该距离的凸块对;超过limit时提前返回
+// 先取使相交的块对都分离的距离,移动后若又碰到其他块,再越过这些块,直到不再相交
+// 凸块对沿直线移动时相交的范围是一个区间,每对至多越过一次,最终一定分离
+// 凹形状分离后继续移动可能再次相交,Depth只保证恰好移出时分离
+func separation(ca, cb []*collider, hits [][2]*collider, n vec.Vec2[float32], limit float64) (float64, [2]*collider) {
+	d := 0.0
+	var binding [2]*collider
+	for _, h := range hits {
+		if p := exitDist(h, n, 0, d, limit); p > d || binding[0] == nil {
+			d, binding = max(p, d), h
+		}
+		if d >= limit {
+			return d, binding
+		}
+	}
+	for iter := 0; iter <= len(ca)*len(cb) && d < limit; iter++ {
+		eps := 1e-4 * math.Max(1, math.Abs(d))
+		off := vec.Vec2[float32]{X: n.X * float32(d+eps), Y: n.Y * float32(d+eps)}
+		clear := true
+		for _, pa := range ca {
+			for _, pb := range cb {
+				if !convexIntersects(pa, pb.moved(off)) {
+					continue
+				}
+				clear = false
+				if p := exitDist([2]*collider{pa, pb}, n, d+eps, d, limit); p > d {
+					d, binding = p, [2]*collider{pa, pb}
+				}
+			}
+		}
+		if clear {
+			break
+		}
+	}
+	return d, binding
+}
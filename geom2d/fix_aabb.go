@@ -0,0 +1,27 @@
+package geom2d
+
+import (
+	"github.com/deminzhang/go-common/fix64"
+	"github.com/deminzhang/go-common/vec"
+)
+
+type FixAABB struct {
+	FixBaseShape
+	Width  fix64.Fix64
+	Height fix64.Fix64
+}
+
+func NewFixAABB(x, y, width, height fix64.Fix64) *FixAABB {
+	return &FixAABB{FixBaseShape: FixBaseShape{Pos: vec.FixVector2{X: x, Y: y}}, Width: width, Height: height}
+}
+
+func (a *FixAABB) Intersects(target IFixShape) bool {
+	switch other := target.(type) {
+	case *FixCircle:
+		return other.Intersects(a)
+	case *FixPoint, *FixLineSegment, *FixTriangle, *FixSector, *FixAABB, *FixOBB:
+		// 按旋转角为0的OBB处理
+		return (&FixOBB{FixAABB: *a}).Intersects(other)
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package geom2d
+
+import (
+	"github.com/deminzhang/go-common/fix64"
+	"github.com/deminzhang/go-common/vec"
+)
+
+type FixCircle struct {
+	FixBaseShape
+	Radius fix64.Fix64
+}
+
+func NewFixCircle(x, y, r fix64.Fix64) *FixCircle {
+	return &FixCircle{FixBaseShape: FixBaseShape{Pos: vec.FixVector2{X: x, Y: y}}, Radius: r}
+}
+
+func (c *FixCircle) Intersects(target IFixShape) bool {
+	switch other := target.(type) {
+	case *FixPoint:
+		return other.intersectsCircle(c)
+	case *FixCircle:
+		return c.intersectsCircle(other)
+	case *FixSector:
+		return c.intersectsSector(other)
+	case *FixAABB:
+		// AABB上离圆心最近的点
+		hw, hh := other.Width/2, other.Height/2
+		dx := c.Pos.X - other.Pos.X
+		dy := c.Pos.Y - other.Pos.Y
+		d := vec.FixVector2{X: dx - fixClamp(dx, -hw, hw), Y: dy - fixClamp(dy, -hh, hh)}
+		return d.SqrMagnitude() <= c.Radius.Mul(c.Radius)
+	case *FixOBB:
+		return other.Intersects(c)
+	case *FixLineSegment:
+		return other.Intersects(c)
+	case *FixTriangle:
+		return other.Intersects(c)
+	}
+	return false
+}
+
+func (c *FixCircle) intersectsCircle(other *FixCircle) bool {
+	r := c.Radius + other.Radius
+	return vec.DistanceSqrFV2(c.Pos, other.Pos) < r.Mul(r)
+}
+
+func (c *FixCircle) intersectsSector(sector *FixSector) bool {
+	// 圆心在扇形内
+	if pointInFixSector(c.Pos, sector) {
+		return true
+	}
+	r := c.Radius + sector.Radius
+	if vec.DistanceSqrFV2(c.Pos, sector.Pos) > r.Mul(r) {
+		return false
+	}
+	// 圆弧上离圆心最近的点
+	d := vec.SubFV2(c.Pos, sector.Pos)
+	if fixAngleInSector(d, sector) {
+		d.ScaleToLength(sector.Radius)
+		if vec.DistanceSqrFV2(c.Pos, vec.AddFV2(sector.Pos, d)) <= c.Radius.Mul(c.Radius) {
+			return true
+		}
+	}
+	// 两条半径
+	pStart, pEnd := fixSectorEnds(sector)
+	return fixSegmentIntersectsCircle(sector.Pos, pStart, c.Pos, c.Radius) ||
+		fixSegmentIntersectsCircle(sector.Pos, pEnd, c.Pos, c.Radius)
+}
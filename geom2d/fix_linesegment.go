@@ -0,0 +1,44 @@
+package geom2d
+
+import (
+	"github.com/deminzhang/go-common/fix64"
+	"github.com/deminzhang/go-common/vec"
+)
+
+type FixLineSegment struct {
+	P1, P2 FixPoint
+}
+
+func NewFixLineSegment(x1, y1, x2, y2 fix64.Fix64) *FixLineSegment {
+	return &FixLineSegment{P1: *NewFixPoint(x1, y1), P2: *NewFixPoint(x2, y2)}
+}
+
+func (ls *FixLineSegment) Intersects(target IFixShape) bool {
+	switch other := target.(type) {
+	case *FixPoint:
+		return other.isOnLineSegment(ls.P1.Pos, ls.P2.Pos)
+	case *FixCircle:
+		return fixSegmentIntersectsCircle(ls.P1.Pos, ls.P2.Pos, other.Pos, other.Radius)
+	case *FixSector:
+		return fixSectorIntersectsPolygon(other, []vec.FixVector2{ls.P1.Pos, ls.P2.Pos})
+	case *FixOBB:
+		return other.Intersects(ls)
+	case *FixAABB:
+		return other.Intersects(ls)
+	case *FixTriangle:
+		if pointInFixTriangle(ls.P1.Pos, other.A.Pos, other.B.Pos, other.C.Pos) || pointInFixTriangle(ls.P2.Pos, other.A.Pos, other.B.Pos, other.C.Pos) {
+			return true
+		}
+		return fixSegmentIntersectsSegment(ls.P1.Pos, ls.P2.Pos, other.A.Pos, other.B.Pos) ||
+			fixSegmentIntersectsSegment(ls.P1.Pos, ls.P2.Pos, other.B.Pos, other.C.Pos) ||
+			fixSegmentIntersectsSegment(ls.P1.Pos, ls.P2.Pos, other.C.Pos, other.A.Pos)
+	case *FixLineSegment:
+		return fixSegmentIntersectsSegment(ls.P1.Pos, ls.P2.Pos, other.P1.Pos, other.P2.Pos)
+	}
+	return false
+}
+
+func (ls *FixLineSegment) Move(delta vec.FixVector2) {
+	ls.P1.Pos.Add(delta)
+	ls.P2.Pos.Add(delta)
+}
@@ -0,0 +1,72 @@
+package geom2d
+
+import (
+	"github.com/deminzhang/go-common/fix64"
+	"github.com/deminzhang/go-common/vec"
+)
+
+type FixOBB struct {
+	FixAABB
+	Angle fix64.Fix64 // 旋转角度（度）
+}
+
+func NewFixOBB(x, y, width, height, angle fix64.Fix64) *FixOBB {
+	return &FixOBB{FixAABB: FixAABB{FixBaseShape: FixBaseShape{Pos: vec.FixVector2{X: x, Y: y}}, Width: width, Height: height}, Angle: angle}
+}
+
+func (r *FixOBB) Intersects(target IFixShape) bool {
+	switch other := target.(type) {
+	case *FixPoint:
+		return pointInFixRectangle(other.Pos, r)
+	case *FixCircle:
+		// 圆心转到矩形局部坐标,求最近点
+		local := fixToLocal(other.Pos, r)
+		hw, hh := r.Width/2, r.Height/2
+		d := vec.FixVector2{X: local.X - fixClamp(local.X, -hw, hw), Y: local.Y - fixClamp(local.Y, -hh, hh)}
+		return d.SqrMagnitude() <= other.Radius.Mul(other.Radius)
+	case *FixOBB:
+		return fixRectRectIntersectSAT(r, other)
+	case *FixAABB:
+		return fixRectRectIntersectSAT(r, &FixOBB{FixAABB: *other})
+	case *FixLineSegment:
+		if pointInFixRectangle(other.P1.Pos, r) || pointInFixRectangle(other.P2.Pos, r) {
+			return true
+		}
+		rc := fixRectangleCorners(r)
+		for i := range rc {
+			if fixSegmentIntersectsSegment(other.P1.Pos, other.P2.Pos, rc[i], rc[(i+1)%4]) {
+				return true
+			}
+		}
+		return false
+	case *FixTriangle:
+		tri := []vec.FixVector2{other.A.Pos, other.B.Pos, other.C.Pos}
+		for _, p := range tri {
+			if pointInFixRectangle(p, r) {
+				return true
+			}
+		}
+		rc := fixRectangleCorners(r)
+		for _, p := range rc {
+			if pointInFixConvex(p, tri) {
+				return true
+			}
+		}
+		for i := range rc {
+			for j := range tri {
+				if fixSegmentIntersectsSegment(rc[i], rc[(i+1)%4], tri[j], tri[(j+1)%3]) {
+					return true
+				}
+			}
+		}
+		return false
+	case *FixSector:
+		return fixSectorIntersectsPolygon(other, fixRectangleCorners(r))
+	}
+	return false
+}
+
+// Corners 四个顶点,逆时针,从(+Width/2,+Height/2)旋转后的位置开始
+func (r *FixOBB) Corners() []vec.FixVector2 {
+	return fixRectangleCorners(r)
+}
@@ -0,0 +1,42 @@
+package geom2d
+
+import (
+	"github.com/deminzhang/go-common/fix64"
+	"github.com/deminzhang/go-common/vec"
+)
+
+type FixPoint struct {
+	FixBaseShape
+}
+
+func NewFixPoint(x, y fix64.Fix64) *FixPoint {
+	return &FixPoint{FixBaseShape: FixBaseShape{Pos: vec.FixVector2{X: x, Y: y}}}
+}
+
+func (p *FixPoint) Intersects(target IFixShape) bool {
+	switch other := target.(type) {
+	case *FixPoint:
+		return p.Pos.Equal(other.Pos)
+	case *FixCircle:
+		return p.intersectsCircle(other)
+	case *FixSector:
+		return pointInFixSector(p.Pos, other)
+	case *FixLineSegment:
+		return p.isOnLineSegment(other.P1.Pos, other.P2.Pos)
+	case *FixOBB:
+		return pointInFixRectangle(p.Pos, other)
+	case *FixAABB:
+		return pointInFixRectangle(p.Pos, &FixOBB{FixAABB: *other})
+	case *FixTriangle:
+		return pointInFixTriangle(p.Pos, other.A.Pos, other.B.Pos, other.C.Pos)
+	}
+	return false
+}
+
+func (p *FixPoint) intersectsCircle(circle *FixCircle) bool {
+	return vec.DistanceSqrFV2(p.Pos, circle.Pos) < circle.Radius.Mul(circle.Radius)
+}
+
+func (p *FixPoint) isOnLineSegment(start, end vec.FixVector2) bool {
+	return fixCross(vec.SubFV2(end, start), vec.SubFV2(p.Pos, start)) == 0 && fixOnSegment(start, end, p.Pos)
+}
@@ -0,0 +1,61 @@
+package geom2d
+
+import (
+	"github.com/deminzhang/go-common/fix64"
+	"github.com/deminzhang/go-common/vec"
+)
+
+type FixSector struct {
+	FixCircle
+	StartAngle fix64.Fix64 // 起始角度（度）
+	EndAngle   fix64.Fix64 // 结束角度（度）
+}
+
+func NewFixSector(x, y, radius, startAngle, endAngle fix64.Fix64) *FixSector {
+	return &FixSector{FixCircle: FixCircle{FixBaseShape: FixBaseShape{Pos: vec.FixVector2{X: x, Y: y}}, Radius: radius}, StartAngle: startAngle, EndAngle: endAngle}
+}
+
+func (s *FixSector) Intersects(target IFixShape) bool {
+	switch other := target.(type) {
+	case *FixPoint:
+		return pointInFixSector(other.Pos, s)
+	case *FixCircle:
+		return other.intersectsSector(s)
+	case *FixSector:
+		return s.intersectsSector(other)
+	case *FixOBB:
+		return other.Intersects(s)
+	case *FixAABB:
+		return other.Intersects(s)
+	case *FixLineSegment:
+		return other.Intersects(s)
+	case *FixTriangle:
+		return other.Intersects(s)
+	}
+	return false
+}
+
+// intersectsSector 一方的半径与另一方相交(含圆心在另一方内),或两段圆弧相交
+func (s *FixSector) intersectsSector(other *FixSector) bool {
+	r := s.Radius + other.Radius
+	if vec.DistanceSqrFV2(s.Pos, other.Pos) > r.Mul(r) {
+		return false
+	}
+	sStart, sEnd := fixSectorEnds(s)
+	oStart, oEnd := fixSectorEnds(other)
+	if fixSectorIntersectsPolygon(other, []vec.FixVector2{s.Pos, sStart}) ||
+		fixSectorIntersectsPolygon(other, []vec.FixVector2{s.Pos, sEnd}) ||
+		fixSectorIntersectsPolygon(s, []vec.FixVector2{other.Pos, oStart}) ||
+		fixSectorIntersectsPolygon(s, []vec.FixVector2{other.Pos, oEnd}) {
+		return true
+	}
+	pts, ok := fixCircleCircleIntersections(s.Pos, other.Pos, s.Radius, other.Radius)
+	if ok {
+		for _, p := range pts {
+			if fixAngleInSector(vec.SubFV2(p, s.Pos), s) && fixAngleInSector(vec.SubFV2(p, other.Pos), other) {
+				return true
+			}
+		}
+	}
+	return false
+}
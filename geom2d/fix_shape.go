@@ -0,0 +1,20 @@
+package geom2d
+
+import "github.com/deminzhang/go-common/vec"
+
+// IFixShape 定点数图形,与IShape的相交矩阵相同
+type IFixShape interface {
+	Intersects(other IFixShape) bool
+}
+
+type FixBaseShape struct {
+	Pos vec.FixVector2
+}
+
+func (bs *FixBaseShape) Intersects(other IFixShape) bool {
+	return false
+}
+
+func (bs *FixBaseShape) Move(delta vec.FixVector2) {
+	bs.Pos.Add(delta)
+}
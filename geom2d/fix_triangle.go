@@ -0,0 +1,62 @@
+package geom2d
+
+import (
+	"github.com/deminzhang/go-common/fix64"
+	"github.com/deminzhang/go-common/vec"
+)
+
+type FixTriangle struct {
+	A, B, C FixPoint
+}
+
+func NewFixTriangle(ax, ay, bx, by, cx, cy fix64.Fix64) *FixTriangle {
+	return &FixTriangle{A: *NewFixPoint(ax, ay), B: *NewFixPoint(bx, by), C: *NewFixPoint(cx, cy)}
+}
+
+func (t *FixTriangle) points() []vec.FixVector2 {
+	return []vec.FixVector2{t.A.Pos, t.B.Pos, t.C.Pos}
+}
+
+func (t *FixTriangle) Intersects(target IFixShape) bool {
+	switch other := target.(type) {
+	case *FixPoint:
+		return pointInFixTriangle(other.Pos, t.A.Pos, t.B.Pos, t.C.Pos)
+	case *FixCircle:
+		// 圆心在三角形内,或任一边与圆相交
+		if pointInFixTriangle(other.Pos, t.A.Pos, t.B.Pos, t.C.Pos) {
+			return true
+		}
+		return fixSegmentIntersectsCircle(t.A.Pos, t.B.Pos, other.Pos, other.Radius) ||
+			fixSegmentIntersectsCircle(t.B.Pos, t.C.Pos, other.Pos, other.Radius) ||
+			fixSegmentIntersectsCircle(t.C.Pos, t.A.Pos, other.Pos, other.Radius)
+	case *FixOBB:
+		return other.Intersects(t)
+	case *FixAABB:
+		return other.Intersects(t)
+	case *FixLineSegment:
+		return other.Intersects(t)
+	case *FixTriangle:
+		a, b := t.points(), other.points()
+		for _, p := range a {
+			if pointInFixConvex(p, b) {
+				return true
+			}
+		}
+		for _, p := range b {
+			if pointInFixConvex(p, a) {
+				return true
+			}
+		}
+		for i := range a {
+			for j := range b {
+				if fixSegmentIntersectsSegment(a[i], a[(i+1)%3], b[j], b[(j+1)%3]) {
+					return true
+				}
+			}
+		}
+		return false
+	case *FixSector:
+		return fixSectorIntersectsPolygon(other, t.points())
+	}
+	return false
+}
@@ -0,0 +1,263 @@
+package geom2d
+
+import (
+	"github.com/deminzhang/go-common/fix64"
+	"github.com/deminzhang/go-common/vec"
+)
+
+// 定点数版本的几何运算,只用整数运算,各平台结果一致,用于帧同步的命中判定
+// 平方距离在Fix64范围内,坐标绝对值需小于约3万
+
+var (
+	fix180 = fix64.FromInt(180)
+	fix360 = fix64.FromInt(360)
+)
+
+// fixSinCosDeg 角度(度)的正弦和余弦,90度的整数倍时精确
+func fixSinCosDeg(deg fix64.Fix64) (sin, cos fix64.Fix64) {
+	return deg.SinCosDeg()
+}
+
+// fixDirDeg 角度(度)方向的单位向量
+func fixDirDeg(deg fix64.Fix64) vec.FixVector2 {
+	s, c := fixSinCosDeg(deg)
+	return vec.FixVector2{X: c, Y: s}
+}
+
+func normalizeFixDeg(a fix64.Fix64) fix64.Fix64 {
+	a %= fix360
+	if a < 0 {
+		return a + fix360
+	}
+	return a
+}
+
+func fixDot(u, v vec.FixVector2) fix64.Fix64 {
+	return u.X.Mul(v.X) + u.Y.Mul(v.Y)
+}
+
+func fixCross(u, v vec.FixVector2) fix64.Fix64 {
+	return u.X.Mul(v.Y) - u.Y.Mul(v.X)
+}
+
+func fixClamp(v, lo, hi fix64.Fix64) fix64.Fix64 {
+	return min(max(v, lo), hi)
+}
+
+// fixToLocal p相对矩形中心的局部坐标
+func fixToLocal(p vec.FixVector2, r *FixOBB) vec.FixVector2 {
+	d := vec.SubFV2(p, r.Pos)
+	s, c := fixSinCosDeg(r.Angle)
+	return vec.FixVector2{X: c.Mul(d.X) + s.Mul(d.Y), Y: c.Mul(d.Y) - s.Mul(d.X)}
+}
+
+func pointInFixRectangle(p vec.FixVector2, r *FixOBB) bool {
+	local := fixToLocal(p, r)
+	return local.X.Abs() <= r.Width/2 && local.Y.Abs() <= r.Height/2
+}
+
+// pointInFixConvex p是否在凸多边形内(含边上),顶点顺逆时针均可
+func pointInFixConvex(p vec.FixVector2, pts []vec.FixVector2) bool {
+	var pos, neg bool
+	for i, a := range pts {
+		b := pts[(i+1)%len(pts)]
+		c := fixCross(vec.SubFV2(b, a), vec.SubFV2(p, a))
+		pos = pos || c > 0
+		neg = neg || c < 0
+	}
+	return !(pos && neg)
+}
+
+func pointInFixTriangle(p, a, b, c vec.FixVector2) bool {
+	return pointInFixConvex(p, []vec.FixVector2{a, b, c})
+}
+
+// fixAngleInSector 方向d是否在扇形的角度范围内(逆时针从StartAngle到EndAngle)
+func fixAngleInSector(d vec.FixVector2, s *FixSector) bool {
+	if d.X == 0 && d.Y == 0 {
+		return true
+	}
+	sd, ed := fixDirDeg(s.StartAngle), fixDirDeg(s.EndAngle)
+	span := normalizeFixDeg(s.EndAngle) - normalizeFixDeg(s.StartAngle)
+	if span < 0 {
+		span += fix360
+	}
+	switch {
+	case span == 0:
+		return fixCross(sd, d) == 0 && fixDot(sd, d) > 0
+	case span <= fix180:
+		return fixCross(sd, d) >= 0 && fixCross(d, ed) >= 0
+	}
+	return !(fixCross(ed, d) > 0 && fixCross(d, sd) > 0)
+}
+
+func pointInFixSector(p vec.FixVector2, s *FixSector) bool {
+	d := vec.SubFV2(p, s.Pos)
+	if d.SqrMagnitude() > s.Radius.Mul(s.Radius) {
+		return false
+	}
+	return fixAngleInSector(d, s)
+}
+
+// fixSectorEnds 扇形两条半径的外端点
+func fixSectorEnds(s *FixSector) (start, end vec.FixVector2) {
+	start = fixDirDeg(s.StartAngle)
+	start.Multiply(s.Radius)
+	end = fixDirDeg(s.EndAngle)
+	end.Multiply(s.Radius)
+	return vec.AddFV2(s.Pos, start), vec.AddFV2(s.Pos, end)
+}
+
+func fixClosestPointOnSegment(p, a, b vec.FixVector2) vec.FixVector2 {
+	d := vec.SubFV2(b, a)
+	dd := fixDot(d, d)
+	if dd == 0 {
+		return a
+	}
+	t := fixClamp(fixDot(vec.SubFV2(p, a), d).Div(dd), 0, fix64.FixOne)
+	d.Multiply(t)
+	return vec.AddFV2(a, d)
+}
+
+func fixDistPointToSegmentSq(p, a, b vec.FixVector2) fix64.Fix64 {
+	return vec.DistanceSqrFV2(p, fixClosestPointOnSegment(p, a, b))
+}
+
+func fixSegmentIntersectsCircle(p1, p2, center vec.FixVector2, r fix64.Fix64) bool {
+	return fixDistPointToSegmentSq(center, p1, p2) <= r.Mul(r)
+}
+
+func fixSegmentIntersectsSegment(p1, p2, q1, q2 vec.FixVector2) bool {
+	orient := func(a, b, c vec.FixVector2) fix64.Fix64 {
+		return fixCross(vec.SubFV2(b, a), vec.SubFV2(c, a))
+	}
+	o1 := orient(p1, p2, q1)
+	o2 := orient(p1, p2, q2)
+	o3 := orient(q1, q2, p1)
+	o4 := orient(q1, q2, p2)
+	if (o1 == 0 && fixOnSegment(p1, p2, q1)) || (o2 == 0 && fixOnSegment(p1, p2, q2)) ||
+		(o3 == 0 && fixOnSegment(q1, q2, p1)) || (o4 == 0 && fixOnSegment(q1, q2, p2)) {
+		return true
+	}
+	return (o1 > 0 && o2 < 0 || o1 < 0 && o2 > 0) && (o3 > 0 && o4 < 0 || o3 < 0 && o4 > 0)
+}
+
+// fixOnSegment 共线的c是否在ab的包围盒内
+func fixOnSegment(a, b, c vec.FixVector2) bool {
+	return c.X >= min(a.X, b.X) && c.X <= max(a.X, b.X) && c.Y >= min(a.Y, b.Y) && c.Y <= max(a.Y, b.Y)
+}
+
+// fixSegmentIntersectsArc 线段是否与扇形的圆弧相交
+func fixSegmentIntersectsArc(a, b vec.FixVector2, s *FixSector) bool {
+	d := vec.SubFV2(b, a)
+	dd := fixDot(d, d)
+	if dd == 0 {
+		return false
+	}
+	// 圆心在直线上的投影参数t0,半弦长对应的参数dt
+	t0 := fixDot(vec.SubFV2(s.Pos, a), d).Div(dd)
+	q := d
+	q.Multiply(t0)
+	h2 := s.Radius.Mul(s.Radius) - vec.DistanceSqrFV2(s.Pos, vec.AddFV2(a, q))
+	if h2 < 0 {
+		return false
+	}
+	dt := h2.Div(dd).Sqrt()
+	for _, t := range [2]fix64.Fix64{t0 - dt, t0 + dt} {
+		if t < 0 || t > fix64.FixOne {
+			continue
+		}
+		p := d
+		p.Multiply(t)
+		if fixAngleInSector(vec.SubFV2(vec.AddFV2(a, p), s.Pos), s) {
+			return true
+		}
+	}
+	return false
+}
+
+// fixSectorIntersectsPolygon 扇形与凸多边形(2个点时为线段)是否相交:
+// 顶点在扇形内,扇形圆心在多边形内,或边与扇形的半径、圆弧相交
+func fixSectorIntersectsPolygon(s *FixSector, pts []vec.FixVector2) bool {
+	for _, p := range pts {
+		if pointInFixSector(p, s) {
+			return true
+		}
+	}
+	if len(pts) > 2 && pointInFixConvex(s.Pos, pts) {
+		return true
+	}
+	pStart, pEnd := fixSectorEnds(s)
+	n := len(pts)
+	if n == 2 {
+		n = 1
+	}
+	for i := 0; i < n; i++ {
+		a, b := pts[i], pts[(i+1)%len(pts)]
+		if fixSegmentIntersectsSegment(a, b, s.Pos, pStart) || fixSegmentIntersectsSegment(a, b, s.Pos, pEnd) ||
+			fixSegmentIntersectsArc(a, b, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func fixRectangleCorners(r *FixOBB) []vec.FixVector2 {
+	hw, hh := r.Width/2, r.Height/2
+	s, c := fixSinCosDeg(r.Angle)
+	corners := [4]vec.FixVector2{{X: hw, Y: hh}, {X: -hw, Y: hh}, {X: -hw, Y: -hh}, {X: hw, Y: -hh}}
+	res := make([]vec.FixVector2, 0, 4)
+	for _, p := range corners {
+		res = append(res, vec.FixVector2{X: r.Pos.X + p.X.Mul(c) - p.Y.Mul(s), Y: r.Pos.Y + p.X.Mul(s) + p.Y.Mul(c)})
+	}
+	return res
+}
+
+func fixRectAxes(r *FixOBB) []vec.FixVector2 {
+	s, c := fixSinCosDeg(r.Angle)
+	return []vec.FixVector2{{X: c, Y: s}, {X: -s, Y: c}}
+}
+
+func fixProjectPointsAxis(points []vec.FixVector2, axis vec.FixVector2) (fix64.Fix64, fix64.Fix64) {
+	lo, hi := fix64.FixMax, fix64.FixMin
+	for _, p := range points {
+		proj := fixDot(p, axis)
+		lo = min(lo, proj)
+		hi = max(hi, proj)
+	}
+	return lo, hi
+}
+
+func fixRectRectIntersectSAT(a, b *FixOBB) bool {
+	aCorners := fixRectangleCorners(a)
+	bCorners := fixRectangleCorners(b)
+	for _, axis := range append(fixRectAxes(a), fixRectAxes(b)...) {
+		minA, maxA := fixProjectPointsAxis(aCorners, axis)
+		minB, maxB := fixProjectPointsAxis(bCorners, axis)
+		if maxA < minB || maxB < minA {
+			return false
+		}
+	}
+	return true
+}
+
+// fixCircleCircleIntersections 两圆的交点(0,1,2个)
+func fixCircleCircleIntersections(c1, c2 vec.FixVector2, r1, r2 fix64.Fix64) ([]vec.FixVector2, bool) {
+	dv := vec.SubFV2(c2, c1)
+	d := dv.Magnitude()
+	if d == 0 || d > r1+r2 || d < (r1-r2).Abs() {
+		return nil, false
+	}
+	a := (r1.Mul(r1) - r2.Mul(r2) + d.Mul(d)).Div(2 * d)
+	h2 := r1.Mul(r1) - a.Mul(a)
+	h := fix64.FixZero
+	if h2 > 0 {
+		h = h2.Sqrt()
+	}
+	m := vec.FixVector2{X: c1.X + a.Mul(dv.X).Div(d), Y: c1.Y + a.Mul(dv.Y).Div(d)}
+	off := vec.FixVector2{X: -h.Mul(dv.Y).Div(d), Y: h.Mul(dv.X).Div(d)}
+	if h == 0 {
+		return []vec.FixVector2{m}, true
+	}
+	return []vec.FixVector2{vec.AddFV2(m, off), vec.SubFV2(m, off)}, true
+}
@@ -1,7 +1,14 @@
 package geom2d
 
 import (
+	"cmp"
+	"math"
+	"math/rand"
+	"slices"
 	"testing"
+
+	"github.com/deminzhang/go-common/fix64"
+	"github.com/deminzhang/go-common/vec"
 )
 
 func TestPointRectangle(t *testing.T) {
@@ -83,3 +90,931 @@ func TestRectAABB(t *testing.T) {
 		t.Fatalf("expected OBB and AABB intersect")
 	}
 }
+
+func TestSectorShapes(t *testing.T) {
+	// 终止角超过360度
+	s := NewSector(0, 0, 10, 300, 420)
+	if !NewPoint(4.33, 2.5).Intersects(s) || NewPoint(-4.33, 2.5).Intersects(s) {
+		t.Fatalf("point vs sector past 360")
+	}
+	// 只穿过终止半径的三角形
+	s = NewSector(0, 0, 10, 0, 90)
+	if tri := NewTriangle(-2, 3, -2, 5, 5, 9); !tri.Intersects(s) {
+		t.Fatalf("expected triangle intersect end radius")
+	}
+	// 半径只与对方的圆相交,不与对方的扇形相交
+	s1 := NewSector(0, 0, 10, 90, 100)
+	s2 := NewSector(15, 0, 10, 170, 190)
+	if s1.Intersects(s2) || s2.Intersects(s1) {
+		t.Fatalf("expected sectors not intersect")
+	}
+	if !NewAABB(0, 9, 2, 2).Intersects(s) || NewAABB(-3, -3, 2, 2).Intersects(s) {
+		t.Fatalf("AABB vs sector")
+	}
+	// OBB和线段改用sectorIntersectsPolygon,逐个分支检查,结果与改写前相同:
+	// 顶点在内,扇形圆心在内,穿过半径,只穿过圆弧,在圆外,在角度范围外
+	for i, c := range []struct {
+		shape IShape
+		want  bool
+	}{
+		{NewOBB(5, 5, 2, 2, 0), true}, {NewOBB(0, 0, 40, 40, 0), true}, {NewOBB(5, 3.5, 1, 13, 0), true},
+		{NewOBB(7.07, 7.07, 10, 0.5, -45), true}, {NewOBB(15, 15, 2, 2, 0), false}, {NewOBB(-5, 5, 2, 2, 0), false},
+		{NewLineSegment(5, -1, 5, 12), true}, {NewLineSegment(3, 11, 11, 3), true}, {NewLineSegment(-1, -1, -5, -8), false},
+		{NewTriangle(-20, -20, 20, -20, 0, 20), true},
+	} {
+		if c.shape.Intersects(s) != c.want || s.Intersects(c.shape) != c.want {
+			t.Errorf("case %d: %T vs sector want %v", i, c.shape, c.want)
+		}
+	}
+}
+
+// shapeParams 随机图形参数,取1/16的整数倍,float32和Fix64都能精确表示
+type shapeParams struct {
+	kind int
+	v    [6]float32
+}
+
+const shapeKinds = 7
+
+func randShapeParams(r *rand.Rand) shapeParams {
+	coord := func() float32 { return float32(r.Intn(257)-128) / 16 }
+	size := func() float32 { return float32(r.Intn(89)+8) / 16 }
+	p := shapeParams{kind: r.Intn(shapeKinds)}
+	switch p.kind {
+	case 0: // Point
+		p.v = [6]float32{coord(), coord()}
+	case 1: // Circle
+		p.v = [6]float32{coord(), coord(), size()}
+	case 2: // AABB
+		p.v = [6]float32{coord(), coord(), size(), size()}
+	case 3: // OBB
+		p.v = [6]float32{coord(), coord(), size(), size(), float32(r.Intn(360))}
+	case 4: // Sector
+		start := float32(r.Intn(360))
+		p.v = [6]float32{coord(), coord(), size(), start, start + float32(r.Intn(341)+10)}
+	case 5: // Triangle
+		p.v = [6]float32{coord(), coord(), coord(), coord(), coord(), coord()}
+	case 6: // LineSegment
+		p.v = [6]float32{coord(), coord(), coord(), coord()}
+	}
+	return p
+}
+
+func (p shapeParams) shape(dx, dy float32) IShape {
+	v := p.v
+	switch p.kind {
+	case 0:
+		return NewPoint(v[0]+dx, v[1]+dy)
+	case 1:
+		return NewCircle(v[0]+dx, v[1]+dy, v[2])
+	case 2:
+		return NewAABB(v[0]+dx, v[1]+dy, v[2], v[3])
+	case 3:
+		return NewOBB(v[0]+dx, v[1]+dy, v[2], v[3], v[4])
+	case 4:
+		return NewSector(v[0]+dx, v[1]+dy, v[2], v[3], v[4])
+	case 5:
+		return NewTriangle(v[0]+dx, v[1]+dy, v[2]+dx, v[3]+dy, v[4]+dx, v[5]+dy)
+	}
+	return NewLineSegment(v[0]+dx, v[1]+dy, v[2]+dx, v[3]+dy)
+}
+
+func (p shapeParams) fixShape() IFixShape {
+	var v [6]fix64.Fix64
+	for i, f := range p.v {
+		v[i] = fix64.FromFloat(f)
+	}
+	switch p.kind {
+	case 0:
+		return NewFixPoint(v[0], v[1])
+	case 1:
+		return NewFixCircle(v[0], v[1], v[2])
+	case 2:
+		return NewFixAABB(v[0], v[1], v[2], v[3])
+	case 3:
+		return NewFixOBB(v[0], v[1], v[2], v[3], v[4])
+	case 4:
+		return NewFixSector(v[0], v[1], v[2], v[3], v[4])
+	case 5:
+		return NewFixTriangle(v[0], v[1], v[2], v[3], v[4], v[5])
+	}
+	return NewFixLineSegment(v[0], v[1], v[2], v[3])
+}
+
+// TestFixShapesMatchFloat 随机图形对,float版结果在小范围平移下不变时,定点版须与之一致
+func TestFixShapesMatchFloat(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	const eps = 0.05
+	offsets := [][2]float32{{eps, 0}, {-eps, 0}, {0, eps}, {0, -eps}, {eps, eps}, {-eps, -eps}, {eps, -eps}, {-eps, eps}}
+	var checked [shapeKinds][shapeKinds]int
+	for i := 0; i < 100000; i++ {
+		pa, pb := randShapeParams(r), randShapeParams(r)
+		a := pa.shape(0, 0)
+		want := a.Intersects(pb.shape(0, 0))
+		if want != pb.shape(0, 0).Intersects(a) {
+			t.Fatalf("float %T/%T not symmetric: %+v %+v", a, pb.shape(0, 0), pa, pb)
+		}
+		stable := true
+		for _, o := range offsets {
+			if a.Intersects(pb.shape(o[0], o[1])) != want {
+				stable = false
+				break
+			}
+		}
+		if !stable {
+			continue
+		}
+		fa, fb := pa.fixShape(), pb.fixShape()
+		if got := fa.Intersects(fb); got != want {
+			t.Fatalf("%T.Intersects(%T) = %v, float %v: %+v %+v", fa, fb, got, want, pa, pb)
+		}
+		if got := fb.Intersects(fa); got != want {
+			t.Fatalf("%T.Intersects(%T) = %v, float %v: %+v %+v", fb, fa, got, want, pb, pa)
+		}
+		checked[pa.kind][pb.kind]++
+	}
+	for i := range checked {
+		for j := range checked[i] {
+			if checked[i][j] == 0 {
+				t.Errorf("no stable samples for kinds %d/%d", i, j)
+			}
+		}
+	}
+}
+
+func TestFixShapes(t *testing.T) {
+	one := fix64.FixOne
+	s := NewFixSector(0, 0, 5*one, 0, 90*one)
+	if !NewFixCircle(3*one, 3*one, one).Intersects(s) {
+		t.Errorf("expected circle intersect sector")
+	}
+	if NewFixPoint(-one, one).Intersects(s) {
+		t.Errorf("expected point outside sector")
+	}
+	// 跨越0度的扇形
+	wrap := NewFixSector(0, 0, 5*one, 300*one, 30*one)
+	if !NewFixPoint(2*one, -one).Intersects(wrap) || NewFixPoint(-2*one, -one).Intersects(wrap) {
+		t.Errorf("wrapping sector containment wrong")
+	}
+	r := NewFixOBB(0, 0, 4*one, 2*one, 45*one)
+	if !r.Intersects(NewFixPoint(one, one)) || r.Intersects(NewFixPoint(one, -one)) {
+		t.Errorf("rotated OBB containment wrong")
+	}
+	ls := NewFixLineSegment(-10*one, 0, 10*one, 0)
+	if !ls.Intersects(NewFixCircle(0, 0, one)) || !NewFixPoint(3*one, 0).Intersects(ls) {
+		t.Errorf("expected line segment hits")
+	}
+	tri := NewFixTriangle(0, 0, 5*one, 0, 0, 5*one)
+	if !tri.Intersects(NewFixPoint(one, one)) || !tri.Intersects(NewFixAABB(5*one, 5*one, 6*one, 6*one)) {
+		t.Errorf("expected triangle hits")
+	}
+}
+
+func TestCollide(t *testing.T) {
+	c, ok := Collide(NewCircle(0, 0, 2), NewCircle(3, 0, 2))
+	if !ok || c.Normal != (vec.Vec2[float32]{X: 1}) || c.Depth != 1 || len(c.Points) != 1 || c.Points[0] != (vec.Vec2[float32]{X: 1.5}) {
+		t.Errorf("circle/circle contact = %+v, %v", c, ok)
+	}
+	c, ok = Collide(NewAABB(0, 0, 4, 4), NewAABB(3, 1, 4, 4))
+	if !ok || c.Normal != (vec.Vec2[float32]{X: 1}) || c.Depth != 1 || len(c.Points) != 2 {
+		t.Errorf("AABB/AABB contact = %+v, %v", c, ok)
+	}
+	if _, ok := Collide(NewOBB(0, 0, 2, 2, 45), NewPoint(1.2, 0.3)); ok {
+		t.Errorf("point outside rotated OBB collides")
+	}
+	// 超过180度的扇形
+	if _, ok := Collide(NewSector(0, 0, 5, 0, 270), NewCircle(2, -2, 0.5)); ok {
+		t.Errorf("circle in the gap of a 270 degree sector collides")
+	}
+	// 退化为一点的边,接触点不能是NaN
+	box, dot := NewOBB(0, 0, 4, 4, 0), NewLineSegment(1.5, 0, 1.5, 0)
+	if c, ok := Collide(box, dot); !ok || len(c.Points) != 1 || c.Points[0] != (vec.Vec2[float32]{X: 1.5}) {
+		t.Errorf("Collide(box, dot) = %+v %v", c, ok)
+	}
+	if c, ok := Collide(dot, box); !ok || len(c.Points) != 1 || c.Points[0] != (vec.Vec2[float32]{X: 2}) {
+		t.Errorf("Collide(dot, box) = %+v %v", c, ok)
+	}
+	// 圆在270度扇形深处,分割线不能作为接触面: 最近的出口是扇形的缺口,圆移到(1,-1)恰好与两条半径相切
+	if c, ok := Collide(NewCircle(-3, 3, 1), NewSector(0, 0, 10, 0, 270)); !ok || math.Abs(float64(c.Depth)-4*math.Sqrt2) > 1e-4 ||
+		math.Abs(float64(c.Normal.X)+math.Sqrt2/2) > 1e-4 || math.Abs(float64(c.Normal.Y)-math.Sqrt2/2) > 1e-4 {
+		t.Errorf("circle deep in a 270 degree sector = %+v, %v", c, ok)
+	}
+
+	// 随机图形对: 结果与Intersects一致,沿法线移开Depth后不再相交
+	r := rand.New(rand.NewSource(2))
+	const eps = 0.02
+	offsets := [][2]float32{{eps, 0}, {-eps, 0}, {0, eps}, {0, -eps}}
+	var checked [shapeKinds][shapeKinds]int
+	for i := 0; i < 50000; i++ {
+		pa, pb := randShapeParams(r), randShapeParams(r)
+		a, b := pa.shape(0, 0), pb.shape(0, 0)
+		want := a.Intersects(b)
+		stable := true
+		for _, o := range offsets {
+			if a.Intersects(pb.shape(o[0], o[1])) != want {
+				stable = false
+			}
+		}
+		c, ok := Collide(a, b)
+		back, okBack := Collide(b, a)
+		if ok != okBack || ok && math.Abs(float64(c.Depth-back.Depth)) > 1e-3 {
+			t.Fatalf("Collide not symmetric: %+v %v / %+v %v: %+v %+v", c, ok, back, okBack, pa, pb)
+		}
+		if !stable {
+			continue
+		}
+		if ok != want {
+			t.Fatalf("Collide(%T, %T) = %v, Intersects %v: %+v %+v", a, b, ok, want, pa, pb)
+		}
+		if !ok {
+			continue
+		}
+		if math.Abs(float64(c.Normal.Length())-1) > 1e-4 || c.Depth < 0 || len(c.Points) == 0 || len(c.Points) > 2 {
+			t.Fatalf("bad contact %+v: %+v %+v", c, pa, pb)
+		}
+		d := c.Depth + eps
+		if a.Intersects(pb.shape(c.Normal.X*d, c.Normal.Y*d)) {
+			t.Fatalf("still intersects after moving out %+v: %+v %+v", c, pa, pb)
+		}
+		checked[pa.kind][pb.kind]++
+	}
+	for i := range checked {
+		for j := range checked[i] {
+			// 点与点、点与线段几乎不会相交
+			if checked[i][j] == 0 && !(i == 0 && (j == 0 || j == 6) || j == 0 && i == 6) {
+				t.Errorf("no colliding samples for kinds %d/%d", i, j)
+			}
+		}
+	}
+}
+
+// translate 平移形状,测试中模拟运动
+func translate(s IShape, dx, dy float32) {
+	d := vec.Vec2[float32]{X: dx, Y: dy}
+	switch v := s.(type) {
+	case *Point:
+		v.Move(d)
+	case *Circle:
+		v.Move(d)
+	case *AABB:
+		v.Move(d)
+	case *OBB:
+		v.Move(d)
+	case *Sector:
+		v.Move(d)
+	case *LineSegment:
+		v.Move(d)
+	case *Triangle:
+		v.A.Move(d)
+		v.B.Move(d)
+		v.C.Move(d)
+	case *Polygon:
+		v.Move(d)
+	}
+}
+
+func TestBroadPhase(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	// 包围盒须包含形状: 相交的形状包围盒必然重叠
+	for i := 0; i < 20000; i++ {
+		a, b := randShapeParams(r).shape(0, 0), randShapeParams(r).shape(0, 0)
+		ba := shapeBox(a)
+		bb := shapeBox(b)
+		if a.Intersects(b) && !ba.overlaps(bb) {
+			t.Fatalf("%T %+v and %T %+v intersect but boxes %+v %+v don't", a, a, b, b, ba, bb)
+		}
+	}
+
+	factories := map[string]func() BroadPhase{
+		"grid":      func() BroadPhase { return NewHashGrid(8) },
+		"grid-fine": func() BroadPhase { return NewHashGrid(0.25) }, //大形状单独存放,大区域查询遍历所有形状
+		"quadtree":  func() BroadPhase { return NewQuadTree(50, 50, 100, 8) },
+		"aabbtree":  func() BroadPhase { return NewAABBTree(1) },
+	}
+	for name, newIndex := range factories {
+		idx := newIndex()
+		order := map[IShape]int{}
+		var shapes []IShape
+		for i := 0; i < 600; i++ {
+			// 部分形状在世界范围外
+			s := randShapeParams(r).shape(r.Float32()*140-20, r.Float32()*140-20)
+			if !idx.Insert(s) || idx.Insert(s) {
+				t.Fatalf("%s: Insert", name)
+			}
+			order[s] = i
+			shapes = append(shapes, s)
+		}
+		for step := 0; step < 5; step++ {
+			for i := 0; i < len(shapes); i++ {
+				s := shapes[i]
+				switch r.Intn(10) {
+				case 0:
+					if !idx.Remove(s) || idx.Remove(s) || idx.Move(s) {
+						t.Fatalf("%s: Remove", name)
+					}
+					shapes[i] = shapes[len(shapes)-1]
+					shapes = shapes[:len(shapes)-1]
+					i--
+				case 1, 2, 3:
+					translate(s, r.Float32()*4-2, r.Float32()*4-2)
+					if !idx.Move(s) {
+						t.Fatalf("%s: Move", name)
+					}
+				case 4:
+					translate(s, r.Float32()*60-30, r.Float32()*60-30)
+					idx.Move(s)
+				}
+			}
+			if idx.Len() != len(shapes) {
+				t.Fatalf("%s: Len = %d want %d", name, idx.Len(), len(shapes))
+			}
+			var want [][2]IShape
+			for _, a := range shapes {
+				for _, b := range shapes {
+					ba := shapeBox(a)
+					bb := shapeBox(b)
+					if order[a] < order[b] && ba.overlaps(bb) {
+						want = append(want, [2]IShape{a, b})
+					}
+				}
+			}
+			slices.SortFunc(want, func(x, y [2]IShape) int {
+				if c := cmp.Compare(order[x[0]], order[y[0]]); c != 0 {
+					return c
+				}
+				return cmp.Compare(order[x[1]], order[y[1]])
+			})
+			if got := idx.Pairs(); !slices.Equal(got, want) {
+				t.Fatalf("%s: step %d Pairs got %d want %d", name, step, len(got), len(want))
+			}
+
+			region := NewAABB(r.Float32()*100, r.Float32()*100, r.Float32()*40, r.Float32()*40)
+			cx, cy, cr := r.Float32()*100, r.Float32()*100, r.Float32()*20
+			var wantRect, wantCircle []int
+			for _, s := range shapes {
+				b := shapeBox(s)
+				if b.overlaps(aabbBox(region)) {
+					wantRect = append(wantRect, order[s])
+				}
+				if b.overlapsCircle(cx, cy, cr) {
+					wantCircle = append(wantCircle, order[s])
+				}
+			}
+			ids := func(ss []IShape) []int {
+				res := make([]int, len(ss))
+				for i, s := range ss {
+					res[i] = order[s]
+				}
+				slices.Sort(res)
+				return res
+			}
+			slices.Sort(wantRect)
+			slices.Sort(wantCircle)
+			if got := ids(idx.QueryAABB(region)); !slices.Equal(got, wantRect) {
+				t.Fatalf("%s: QueryAABB got %v want %v", name, got, wantRect)
+			}
+			if got := ids(idx.QueryCircle(cx, cy, cr)); !slices.Equal(got, wantCircle) {
+				t.Fatalf("%s: QueryCircle got %v want %v", name, got, wantCircle)
+			}
+		}
+	}
+}
+
+func TestHashGridLimits(t *testing.T) {
+	for _, size := range []float32{0, -1, float32(math.NaN()), float32(math.Inf(1))} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewHashGrid(%v) did not panic", size)
+				}
+			}()
+			NewHashGrid(size)
+		}()
+	}
+	// 坐标超出int32格子范围,覆盖极多格子
+	g := NewHashGrid(1)
+	huge := NewAABB(0, 0, 1e30, 1e30)
+	far := NewCircle(3e38, 3e38, 1)
+	near := NewCircle(-2, 2, 1)
+	edge := NewPoint(float32(math.MaxInt32), 0)
+	for _, s := range []IShape{huge, far, near, edge} {
+		g.Insert(s)
+	}
+	if got := g.QueryAABB(NewAABB(0, 0, 10, 10)); !slices.Equal(got, []IShape{huge, near}) {
+		t.Errorf("QueryAABB = %v", got)
+	}
+	if got := g.QueryAABB(NewAABB(0, 0, 1e38, 1e38)); len(got) != 3 {
+		t.Errorf("QueryAABB whole = %v", got)
+	}
+	if got := g.QueryCircle(3e38, 3e38, 10); !slices.Equal(got, []IShape{far}) {
+		t.Errorf("QueryCircle far = %v", got)
+	}
+	if got := g.QueryCircle(float32(math.MaxInt32), 0, 1); !slices.Equal(got, []IShape{huge, edge}) {
+		t.Errorf("QueryCircle edge = %v", got)
+	}
+	if got := g.Pairs(); len(got) != 2 || got[0] != [2]IShape{huge, near} || got[1] != [2]IShape{huge, edge} {
+		t.Errorf("Pairs = %v", got)
+	}
+	translate(huge, 1e31, 0)
+	g.Move(huge)
+	if got := g.Pairs(); len(got) != 0 || !g.Remove(huge) || g.Len() != 3 {
+		t.Errorf("after Move Pairs = %v", got)
+	}
+}
+
+// benchWorld n个随机小形状,分布在边长size的世界中
+func benchWorld(n int, size float32) []IShape {
+	r := rand.New(rand.NewSource(4))
+	shapes := make([]IShape, n)
+	for i := range shapes {
+		x, y := r.Float32()*size, r.Float32()*size
+		switch i % 3 {
+		case 0:
+			shapes[i] = NewCircle(x, y, r.Float32()*4+1)
+		case 1:
+			shapes[i] = NewAABB(x, y, r.Float32()*8+2, r.Float32()*8+2)
+		default:
+			shapes[i] = NewOBB(x, y, r.Float32()*8+2, r.Float32()*8+2, r.Float32()*360)
+		}
+	}
+	return shapes
+}
+
+// BenchmarkBroadPhase 每帧移动所有形状后求相交对,与两两Intersects比较
+func BenchmarkBroadPhase(b *testing.B) {
+	const n, size = 2000, 1000
+	b.Run("brute", func(b *testing.B) {
+		shapes := benchWorld(n, size)
+		for i := 0; i < b.N; i++ {
+			hits := 0
+			for j, s := range shapes {
+				translate(s, 0.1, -0.1)
+				for _, o := range shapes[:j] {
+					if s.Intersects(o) {
+						hits++
+					}
+				}
+			}
+		}
+	})
+	for _, bc := range []struct {
+		name  string
+		index func() BroadPhase
+	}{
+		{"grid", func() BroadPhase { return NewHashGrid(16) }},
+		{"quadtree", func() BroadPhase { return NewQuadTree(size/2, size/2, size, 8) }},
+		{"aabbtree", func() BroadPhase { return NewAABBTree(2) }},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			shapes := benchWorld(n, size)
+			idx := bc.index()
+			for _, s := range shapes {
+				idx.Insert(s)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				hits := 0
+				for _, s := range shapes {
+					translate(s, 0.1, -0.1)
+					idx.Move(s)
+				}
+				for _, p := range idx.Pairs() {
+					if p[0].Intersects(p[1]) {
+						hits++
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestShapeMeasures(t *testing.T) {
+	near := func(a, b vec.Vec2[float32], eps float64) bool {
+		return math.Hypot(float64(a.X-b.X), float64(a.Y-b.Y)) <= eps
+	}
+	v := func(x, y float32) vec.Vec2[float32] { return vec.Vec2[float32]{X: x, Y: y} }
+
+	if b := NewOBB(1, 2, 2, 2, 45).Bounds(); !near(b.Pos, v(1, 2), 1e-6) || math.Abs(float64(b.Width)-2*math.Sqrt2) > 1e-5 {
+		t.Errorf("OBB Bounds = %+v", b)
+	}
+	s := NewSector(0, 0, 3, 0, 90)
+	if b := s.Bounds(); b.Pos != v(1.5, 1.5) || b.Width != 3 || b.Height != 3 {
+		t.Errorf("Sector Bounds = %+v", b)
+	}
+	if b := NewSector(0, 0, 1, 80, 190).Bounds(); math.Abs(float64(b.Pos.X+b.Width/2)-math.Cos(80*math.Pi/180)) > 1e-6 || b.Height != 1+float32(math.Sin(10*math.Pi/180)) {
+		t.Errorf("Sector Bounds = %+v", b)
+	}
+	if a := s.Area(); math.Abs(float64(a)-9*math.Pi/4) > 1e-5 {
+		t.Errorf("Sector Area = %v", a)
+	}
+	d := 4 * 3 * math.Sin(math.Pi/4) / (3 * math.Pi / 2)
+	if c := s.Centroid(); !near(c, v(float32(d/math.Sqrt2), float32(d/math.Sqrt2)), 1e-5) {
+		t.Errorf("Sector Centroid = %v", c)
+	}
+	if c := NewSector(0, 0, 3, 270, 90).Centroid(); !near(c, v(float32(4/math.Pi), 0), 1e-5) {
+		t.Errorf("half disc Centroid = %v", c)
+	}
+	if a := NewTriangle(0, 0, 4, 0, 0, 3).Area(); a != 6 {
+		t.Errorf("Triangle Area = %v", a)
+	}
+	if !NewTriangle(0, 0, 2, 2, 1, 1).Contains(v(1.5, 1.5)) || NewTriangle(0, 0, 2, 2, 1, 1).Contains(v(1, 0)) {
+		t.Error("degenerate Triangle Contains")
+	}
+	if !NewLineSegment(0, 0, 2, 2).Contains(v(1, 1)) || NewLineSegment(0, 0, 2, 2).Contains(v(1, 0)) {
+		t.Error("LineSegment Contains")
+	}
+	if p := NewCircle(1, 1, 2).ClosestPoint(v(5, 1)); p != v(3, 1) {
+		t.Errorf("Circle ClosestPoint = %v", p)
+	}
+	if p := s.ClosestPoint(v(-1, 2)); !near(p, v(0, 2), 1e-6) {
+		t.Errorf("Sector ClosestPoint = %v", p)
+	}
+	if p := s.ClosestPoint(v(4, 4)); !near(p, v(3/math.Sqrt2, 3/math.Sqrt2), 1e-6) {
+		t.Errorf("Sector ClosestPoint = %v", p)
+	}
+	if p := NewOBB(0, 0, 2, 2, 45).ClosestPoint(v(3, 0)); !near(p, v(math.Sqrt2, 0), 1e-6) {
+		t.Errorf("OBB ClosestPoint = %v", p)
+	}
+
+	// 随机形状: 用包围盒内的均匀采样估计面积和质心,并检查包围盒和最近点
+	r := rand.New(rand.NewSource(5))
+	const samples = 20000
+	for i := 0; i < 300; i++ {
+		sh := randShapeParams(r).shape(0, 0)
+		b := sh.Bounds()
+		bb := aabbBox(&b)
+		ext := bb.expand(1)
+		var inside []vec.Vec2[float32]
+		var sx, sy float64
+		for j := 0; j < samples; j++ {
+			p := v(ext.minX+r.Float32()*(ext.maxX-ext.minX), ext.minY+r.Float32()*(ext.maxY-ext.minY))
+			if !sh.Contains(p) {
+				continue
+			}
+			if !bb.expand(1e-5).overlaps(pointsBox(p)) {
+				t.Fatalf("%T %+v contains %v outside Bounds %+v", sh, sh, p, b)
+			}
+			inside = append(inside, p)
+			sx += float64(p.X)
+			sy += float64(p.Y)
+		}
+		if area := sh.Area(); area > 0.5 {
+			boxArea := float64(ext.maxX-ext.minX) * float64(ext.maxY-ext.minY)
+			est := float64(len(inside)) / samples * boxArea
+			if math.Abs(est-float64(area)) > 0.05*float64(area)+0.05 {
+				t.Fatalf("%T %+v Area = %v, sampled %v", sh, sh, area, est)
+			}
+			// 容差取采样标准误差的几倍
+			n := float64(len(inside))
+			tol := 3 * float64(max(bb.maxX-bb.minX, bb.maxY-bb.minY)) / math.Sqrt(n)
+			if c := sh.Centroid(); !near(c, v(float32(sx/n), float32(sy/n)), tol) {
+				t.Fatalf("%T %+v Centroid = %v, sampled %v,%v", sh, sh, c, sx/n, sy/n)
+			}
+		}
+		for j := 0; j < 20; j++ {
+			q := v(ext.minX+r.Float32()*(ext.maxX-ext.minX), ext.minY+r.Float32()*(ext.maxY-ext.minY))
+			c := sh.ClosestPoint(q)
+			if sh.Contains(q) {
+				if c != q {
+					t.Fatalf("%T %+v ClosestPoint(%v) = %v, want itself", sh, sh, q, c)
+				}
+				continue
+			}
+			if back := sh.ClosestPoint(c); !near(back, c, 1e-4) {
+				t.Fatalf("%T %+v ClosestPoint(%v) = %v not on shape", sh, sh, q, c)
+			}
+			dc := math.Sqrt(float64(c.DistanceSqr(q)))
+			for _, p := range inside {
+				if math.Sqrt(float64(p.DistanceSqr(q))) < dc-1e-4 {
+					t.Fatalf("%T %+v ClosestPoint(%v) = %v but %v is closer", sh, sh, q, c, p)
+				}
+			}
+		}
+	}
+}
+
+// randStarPolygon 围绕(cx,cy)的星形简单多边形,顶点按角度排序,一般是凹的
+func randStarPolygon(r *rand.Rand, cx, cy float32) *Polygon {
+	for {
+		n := r.Intn(10) + 3
+		angles := make([]float64, n)
+		for i := range angles {
+			angles[i] = r.Float64() * 2 * math.Pi
+		}
+		slices.Sort(angles)
+		pts := make([]vec.Vec2[float32], n)
+		for i, a := range angles {
+			d := float64(r.Intn(64)+8) / 16
+			pts[i] = vec.Vec2[float32]{X: cx + float32(math.Round(math.Cos(a)*d*16)/16), Y: cy + float32(math.Round(math.Sin(a)*d*16)/16)}
+		}
+		// 坐标取整后可能自交,重新生成
+		simple := true
+		for i := 0; i < n; i++ {
+			for j := i + 2; j < n; j++ {
+				if (i != 0 || j != n-1) && segmentIntersectsSegment(pts[i], pts[(i+1)%n], pts[j], pts[(j+1)%n]) {
+					simple = false
+				}
+			}
+		}
+		if !simple || signedArea(pts) == 0 {
+			continue
+		}
+		if r.Intn(2) == 0 {
+			slices.Reverse(pts)
+		}
+		return NewPolygon(pts...)
+	}
+}
+
+func TestPolygon(t *testing.T) {
+	v := func(x, y float32) vec.Vec2[float32] { return vec.Vec2[float32]{X: x, Y: y} }
+	square := NewPolygon(v(0, 0), v(2, 0), v(2, 2), v(0, 2))
+	if !square.IsConvex() || !square.IsCCW() || square.Area() != 4 || square.Centroid() != v(1, 1) {
+		t.Errorf("square: convex %v ccw %v area %v centroid %v", square.IsConvex(), square.IsCCW(), square.Area(), square.Centroid())
+	}
+	square.Reverse()
+	if square.IsCCW() || square.SignedArea() != -4 || !square.IsConvex() {
+		t.Error("reversed square")
+	}
+	// L形,缺口在右上
+	l := NewPolygon(v(0, 0), v(4, 0), v(4, 2), v(2, 2), v(2, 4), v(0, 4))
+	if l.IsConvex() || l.Area() != 12 {
+		t.Errorf("L: convex %v area %v", l.IsConvex(), l.Area())
+	}
+	if c := l.Centroid(); math.Abs(float64(c.X)-5.0/3) > 1e-6 || math.Abs(float64(c.Y)-5.0/3) > 1e-6 {
+		t.Errorf("L Centroid = %v", c)
+	}
+	if tris := l.Triangulate(); len(tris) != 4 {
+		t.Errorf("L Triangulate = %d triangles", len(tris))
+	}
+	if parts := l.ConvexDecompose(); len(parts) != 2 {
+		t.Errorf("L ConvexDecompose = %d parts", len(parts))
+	}
+	if l.Contains(v(3, 3)) || !l.Contains(v(1, 3)) || !l.Contains(v(4, 1)) || !l.Contains(v(3, 2)) {
+		t.Error("L Contains")
+	}
+	if p := l.ClosestPoint(v(3, 3)); p != v(3, 2) && p != v(2, 3) {
+		t.Errorf("L ClosestPoint = %v", p)
+	}
+	if l.Intersects(NewCircle(3.2, 3.2, 1)) || !l.Intersects(NewCircle(3, 3, 1.1)) || !NewCircle(3, 3, 1).Intersects(l) {
+		t.Error("L vs circle in the notch")
+	}
+	if l.Intersects(NewAABB(3.5, 3.5, 2.8, 2.8)) || !l.Intersects(NewAABB(3, 3, 2, 2)) {
+		t.Error("L vs box in the notch")
+	}
+	star := NewPolygon(v(0, 3), v(1, -2), v(-2, 1), v(2, 1), v(-1, -2))
+	if star.IsConvex() {
+		t.Error("pentagram is convex")
+	}
+	if c, ok := Collide(l, NewCircle(1, 4.5, 1)); !ok || c.Normal != v(0, 1) || math.Abs(float64(c.Depth)-0.5) > 1e-6 {
+		t.Errorf("Collide L/circle = %+v %v", c, ok)
+	}
+	// 凸分解的对角线不能作为接触面: 最近的表面是x=0的边
+	big := NewPolygon(v(0, 0), v(10, 0), v(10, 2), v(2, 2), v(2, 10), v(0, 10))
+	if c, ok := Collide(NewCircle(1, 1.5, 0.3), big); !ok || math.Abs(float64(c.Normal.X)-1) > 1e-6 || math.Abs(float64(c.Depth)-1.3) > 1e-5 {
+		t.Errorf("Collide circle/L = %+v %v", c, ok)
+	}
+
+	r := rand.New(rand.NewSource(6))
+	// 三角剖分和凸分解覆盖原多边形
+	for i := 0; i < 2000; i++ {
+		pg := randStarPolygon(r, 0, 0)
+		area := float64(pg.Area())
+		var sum float64
+		for _, tri := range pg.Triangulate() {
+			if cross(tri.A.Pos, tri.B.Pos, tri.C.Pos) <= 0 {
+				t.Fatalf("%v: triangle %+v not ccw", pg.Vertices, tri)
+			}
+			sum += float64(tri.Area())
+		}
+		if math.Abs(sum-area) > 1e-4*max(area, 1) {
+			t.Fatalf("%v: triangles area %v want %v", pg.Vertices, sum, area)
+		}
+		sum = 0
+		parts := pg.ConvexDecompose()
+		for _, part := range parts {
+			if !part.IsConvex() || !part.IsCCW() {
+				t.Fatalf("%v: part %v not convex ccw", pg.Vertices, part.Vertices)
+			}
+			sum += float64(part.Area())
+		}
+		if math.Abs(sum-area) > 1e-4*max(area, 1) {
+			t.Fatalf("%v: parts area %v want %v", pg.Vertices, sum, area)
+		}
+		if pg.IsConvex() != (len(parts) == 1) {
+			t.Fatalf("%v: convex %v but %d parts", pg.Vertices, pg.IsConvex(), len(parts))
+		}
+	}
+
+	// 与其他形状的相交: 对称;三角形、矩形转成的多边形与原形状一致;凹多边形与其三角形的并一致
+	const eps = 0.02
+	offsets := [][2]float32{{eps, 0}, {-eps, 0}, {0, eps}, {0, -eps}}
+	var checked [3][shapeKinds]int
+	for i := 0; i < 30000; i++ {
+		ps := randShapeParams(r)
+		other := ps.shape(0, 0)
+		var pg *Polygon
+		var ref func(IShape) bool
+		switch mode := i % 3; mode {
+		case 0:
+			tri := randShapeParams(r)
+			for tri.kind != 5 || tri.shape(0, 0).Area() == 0 {
+				tri = randShapeParams(r)
+			}
+			pg = NewPolygon(v(tri.v[0], tri.v[1]), v(tri.v[2], tri.v[3]), v(tri.v[4], tri.v[5]))
+			ref = tri.shape(0, 0).Intersects
+		case 1:
+			rect := randShapeParams(r)
+			for rect.kind != 3 {
+				rect = randShapeParams(r)
+			}
+			obb := rect.shape(0, 0).(*OBB)
+			pg = NewPolygon(rectangleCorners(obb)...)
+			ref = obb.Intersects
+		default:
+			pg = randStarPolygon(r, float32(r.Intn(9)-4), float32(r.Intn(9)-4))
+			tris := pg.Triangulate()
+			ref = func(s IShape) bool {
+				return slices.ContainsFunc(tris, func(t *Triangle) bool { return t.Intersects(s) })
+			}
+		}
+		got := pg.Intersects(other)
+		if other.Intersects(pg) != got {
+			t.Fatalf("Intersects not symmetric: %v %+v", pg.Vertices, ps)
+		}
+		want := ref(other)
+		stable := true
+		for _, o := range offsets {
+			if ref(ps.shape(o[0], o[1])) != want {
+				stable = false
+			}
+		}
+		if !stable {
+			continue
+		}
+		if got != want {
+			t.Fatalf("mode %d: %v Intersects %T %+v = %v, want %v", i%3, pg.Vertices, other, ps, got, want)
+		}
+		c, ok := Collide(pg, other)
+		if ok != want {
+			t.Fatalf("Collide(%v, %+v) = %v, want %v", pg.Vertices, ps, ok, want)
+		}
+		if !ok {
+			continue
+		}
+		checked[i%3][ps.kind]++
+		// 凹形状移出后可能穿过缺口再次相交,只检查刚好移出时
+		d := c.Depth + 1e-4*max(1, c.Depth)
+		if pg.Intersects(ps.shape(c.Normal.X*d, c.Normal.Y*d)) {
+			t.Fatalf("still intersects after moving out %+v: %v %+v", c, pg.Vertices, ps)
+		}
+	}
+	for i := range checked {
+		for j := range checked[i] {
+			if checked[i][j] == 0 && j != 0 && j != 6 {
+				t.Errorf("no colliding samples for mode %d kind %d", i, j)
+			}
+		}
+	}
+
+	// GJK与分离轴定理一致
+	for i := 0; i < 20000; i++ {
+		a := randStarPolygon(r, 0, 0).colliders()[0]
+		b := randStarPolygon(r, float32(r.Intn(17)-8), float32(r.Intn(17)-8)).colliders()[0]
+		hit, ok := gjkIntersects(a.verts, b.verts)
+		if !ok {
+			t.Fatalf("GJK not converged: %v %v", a.verts, b.verts)
+		}
+		_, want := collideConvex(a, b)
+		if hit != want {
+			t.Fatalf("GJK %v, SAT %v: %v %v", hit, want, a.verts, b.verts)
+		}
+	}
+}
+
+func TestRaycast(t *testing.T) {
+	v := func(x, y float32) vec.Vec2[float32] { return vec.Vec2[float32]{X: x, Y: y} }
+	near := func(a, b vec.Vec2[float32], eps float64) bool {
+		return math.Hypot(float64(a.X-b.X), float64(a.Y-b.Y)) <= eps
+	}
+	l := NewPolygon(v(0, 0), v(4, 0), v(4, 2), v(2, 2), v(2, 4), v(0, 4))
+	for _, c := range []struct {
+		name   string
+		hit    RayHit
+		ok     bool
+		want   RayHit
+		wantOk bool
+	}{
+		{name: "circle", want: RayHit{v(-1, 0), v(-1, 0), 4}, wantOk: true},
+		{name: "aabb", want: RayHit{v(0, -1), v(0, -1), 4}, wantOk: true},
+		{name: "too far"},
+		{name: "sector mouth", want: RayHit{v(0, 0), v(1, 0), 5}, wantOk: true},
+		{name: "notch", want: RayHit{v(3, 2), v(0, 1), 3}, wantOk: true},
+		{name: "inside", want: RayHit{v(0.5, 0), v(0, -1), 0}, wantOk: true},
+		{name: "segment", want: RayHit{v(0, 1), v(0, -1), 2}, wantOk: true},
+		{name: "point", want: RayHit{v(3, 0), v(-1, 0), 3}, wantOk: true},
+		{name: "circle cast", want: RayHit{v(-1, 0), v(-1, 0), 3}, wantOk: true},
+		{name: "circle cast corner", want: RayHit{v(-1, -1), v(-math.Sqrt2/2, -math.Sqrt2/2), float32(2*math.Sqrt2 - 0.5)}, wantOk: true},
+		{name: "aabb cast", want: RayHit{v(-1, 0), v(-1, 0), 3}, wantOk: true},
+		{name: "aabb cast sector", want: RayHit{v(0, 2), v(0, 1), 1}, wantOk: true},
+		{name: "aabb cast miss", wantOk: false},
+	} {
+		switch c.name {
+		case "circle":
+			c.hit, c.ok = Raycast(v(-5, 0), v(2, 0), 10, NewCircle(0, 0, 1))
+		case "aabb":
+			c.hit, c.ok = Raycast(v(0, -5), v(0, 2), 10, NewAABB(0, 0, 2, 2))
+		case "too far":
+			c.hit, c.ok = Raycast(v(0, -5), v(0, 2), 3, NewAABB(0, 0, 2, 2))
+		case "sector mouth":
+			c.hit, c.ok = Raycast(v(5, 0), v(-1, 0), 10, NewSector(0, 0, 2, 45, 315))
+			c.hit.Normal = v(1, 0) //两条半径在圆心相交,法线不确定
+		case "notch":
+			c.hit, c.ok = Raycast(v(3, 5), v(0, -1), 10, l)
+		case "inside":
+			c.hit, c.ok = Raycast(v(0.5, 0), v(0, 1), 10, NewCircle(0, 0, 1))
+		case "segment":
+			c.hit, c.ok = Raycast(v(0, -1), v(0, 1), 10, NewLineSegment(-1, 1, 1, 1))
+		case "point":
+			c.hit, c.ok = Raycast(v(0, 0), v(1, 0), 10, NewPoint(3, 0))
+		case "circle cast":
+			c.hit, c.ok = CircleCast(v(-5, 0), 1, v(1, 0), 10, NewCircle(0, 0, 1))
+		case "circle cast corner":
+			c.hit, c.ok = CircleCast(v(-3, -3), 0.5, v(1, 1), 10, NewAABB(0, 0, 2, 2))
+		case "aabb cast":
+			c.hit, c.ok = AABBCast(NewAABB(-5, 0, 2, 2), v(1, 0), 10, NewCircle(0, 0, 1))
+		case "aabb cast sector":
+			c.hit, c.ok = AABBCast(NewAABB(0.5, 4, 2, 2), v(0, -1), 10, NewSector(0, 0, 2, 0, 180))
+		case "aabb cast miss":
+			c.hit, c.ok = AABBCast(NewAABB(0, 4, 2, 2), v(1, 0), 10, NewSector(0, 0, 2, 0, 180))
+		}
+		if c.ok != c.wantOk || c.ok && (!near(c.hit.Point, c.want.Point, 1e-5) || !near(c.hit.Normal, c.want.Normal, 1e-5) || math.Abs(float64(c.hit.Dist-c.want.Dist)) > 1e-5) {
+			t.Errorf("%s: got %+v %v, want %+v %v", c.name, c.hit, c.ok, c.want, c.wantOk)
+		}
+	}
+	shapes := []IShape{NewCircle(10, 0, 1), NewAABB(5, 0, 1, 1), NewCircle(5, 0, 1)}
+	if h, s, ok := NearestHit(shapes, func(s IShape) (RayHit, bool) { return Raycast(v(0, 0), v(1, 0), 20, s) }); !ok || s != shapes[2] || h.Dist != 4 {
+		t.Errorf("NearestHit = %+v %v %v", h, s, ok)
+	}
+
+	// 随机射线和投射: 命中点在形状上,命中之前的路径上不相交,未命中时整个路径都不相交
+	r := rand.New(rand.NewSource(7))
+	const maxDist, steps = 24, 60
+	for i := 0; i < 6000; i++ {
+		var s IShape
+		if i%8 == 7 {
+			s = randStarPolygon(r, 0, 0)
+		} else {
+			s = randShapeParams(r).shape(0, 0)
+		}
+		origin := v(r.Float32()*24-12, r.Float32()*24-12)
+		dir := v(r.Float32()*2-1, r.Float32()*2-1)
+		d, ok := unitVec(float64(dir.X), float64(dir.Y))
+		if !ok {
+			continue
+		}
+		radius := r.Float32()*2 + 0.1
+		box := NewAABB(0, 0, r.Float32()*3+0.1, r.Float32()*3+0.1)
+		kinds := []struct {
+			name    string
+			hit     func() (RayHit, bool)
+			overlap func(c vec.Vec2[float32]) bool
+			// onBoundary 命中点是否在投射形状的边界上
+			onBoundary func(c, p vec.Vec2[float32]) bool
+		}{
+			{"ray", func() (RayHit, bool) { return Raycast(origin, dir, maxDist, s) },
+				s.Contains,
+				func(c, p vec.Vec2[float32]) bool { return near(c, p, 1e-3) }},
+			{"circle", func() (RayHit, bool) { return CircleCast(origin, radius, dir, maxDist, s) },
+				func(c vec.Vec2[float32]) bool { _, hit := Collide(NewCircle(c.X, c.Y, radius), s); return hit },
+				func(c, p vec.Vec2[float32]) bool { return math.Abs(float64(c.Distance(p)-radius)) < 1e-3 }},
+			{"aabb", func() (RayHit, bool) { box.Pos = origin; return AABBCast(box, dir, maxDist, s) },
+				func(c vec.Vec2[float32]) bool { return NewAABB(c.X, c.Y, box.Width, box.Height).Intersects(s) },
+				func(c, p vec.Vec2[float32]) bool {
+					dx, dy := math.Abs(float64(p.X-c.X))-float64(box.Width/2), math.Abs(float64(p.Y-c.Y))-float64(box.Height/2)
+					return math.Abs(math.Max(dx, dy)) < 1e-3
+				}},
+		}
+		for _, k := range kinds {
+			h, ok := k.hit()
+			end := float64(maxDist)
+			if ok {
+				end = float64(h.Dist) - 1e-2
+				c := v(origin.X+d.X*h.Dist, origin.Y+d.Y*h.Dist)
+				if !near(s.ClosestPoint(h.Point), h.Point, 1e-3) || math.Abs(float64(h.Normal.Length())-1) > 1e-4 {
+					t.Fatalf("%s: %T %+v hit %+v not on shape", k.name, s, s, h)
+				}
+				if h.Dist > 0 && !k.onBoundary(c, h.Point) {
+					t.Fatalf("%s: %T %+v hit %+v not on caster at %v", k.name, s, s, h, c)
+				}
+			}
+			for j := 0; j <= steps && end > 0; j++ {
+				tt := end * float64(j) / steps
+				c := v(origin.X+float32(float64(d.X)*tt), origin.Y+float32(float64(d.Y)*tt))
+				if k.overlap(c) {
+					t.Fatalf("%s: %T %+v overlaps at %v (dist %v) before hit %+v %v, origin %v dir %v", k.name, s, s, c, tt, h, ok, origin, dir)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package geom2d
+
+import "github.com/deminzhang/go-common/vec"
+
+// gjkMaxIter 迭代上限,浮点误差导致不收敛时改用分离轴定理
+const gjkMaxIter = 32
+
+type gjkVec struct{ x, y float64 }
+
+func (a gjkVec) sub(b gjkVec) gjkVec  { return gjkVec{a.x - b.x, a.y - b.y} }
+func (a gjkVec) neg() gjkVec          { return gjkVec{-a.x, -a.y} }
+func (a gjkVec) dot(b gjkVec) float64 { return a.x*b.x + a.y*b.y }
+
+// cross 二维叉积a×b
+func (a gjkVec) cross(b gjkVec) float64 { return a.x*b.y - a.y*b.x }
+
+// perpToward 垂直于a且指向p一侧的向量,p在a所在直线上时返回零向量
+func (a gjkVec) perpToward(p gjkVec) gjkVec {
+	switch c := a.cross(p); {
+	case c > 0:
+		return gjkVec{-a.y, a.x}
+	case c < 0:
+		return gjkVec{a.y, -a.x}
+	}
+	return gjkVec{}
+}
+
+// farthest 点集在方向d上最远的点
+func farthest(pts []vec.Vec2[float32], d gjkVec) gjkVec {
+	best := gjkVec{float64(pts[0].X), float64(pts[0].Y)}
+	bestDot := best.dot(d)
+	for _, p := range pts[1:] {
+		q := gjkVec{float64(p.X), float64(p.Y)}
+		if v := q.dot(d); v > bestDot {
+			best, bestDot = q, v
+		}
+	}
+	return best
+}
+
+// gjkSupport 闵可夫斯基差a-b在方向d上的支撑点
+func gjkSupport(a, b []vec.Vec2[float32], d gjkVec) gjkVec {
+	return farthest(a, d).sub(farthest(b, d.neg()))
+}
+
+// gjkIntersects GJK判断两个凸点集的凸包是否相交,相切视为相交
+// 第二个返回值为false表示未收敛,结果不可信
+func gjkIntersects(a, b []vec.Vec2[float32]) (bool, bool) {
+	d := gjkVec{1, 0}
+	simplex := []gjkVec{gjkSupport(a, b, d)}
+	d = simplex[0].neg()
+	for i := 0; i < gjkMaxIter; i++ {
+		if d == (gjkVec{}) {
+			return true, true //原点在单纯形上
+		}
+		p := gjkSupport(a, b, d)
+		if p.dot(d) < 0 {
+			return false, true //支撑点没越过原点,存在分离轴d
+		}
+		simplex = append(simplex, p)
+		var hit bool
+		simplex, d, hit = gjkNext(simplex)
+		if hit {
+			return true, true
+		}
+	}
+	return false, false
+}
+
+// gjkNext 保留单纯形中离原点最近的特征,返回新的搜索方向;原点在单纯形内或边上时返回true
+// 最后加入的点为a
+func gjkNext(s []gjkVec) ([]gjkVec, gjkVec, bool) {
+	if len(s) == 2 {
+		b, a := s[0], s[1]
+		ab, ao := b.sub(a), a.neg()
+		if ab.dot(ao) <= 0 {
+			return []gjkVec{a}, ao, false
+		}
+		d := ab.perpToward(ao)
+		if d == (gjkVec{}) {
+			// 原点在直线ab上且在a朝b的一侧,没越过b时在线段上
+			if b.neg().dot(a.sub(b)) >= 0 {
+				return s, d, true
+			}
+			return []gjkVec{b}, b.neg(), false
+		}
+		return s, d, false
+	}
+	c, b, a := s[0], s[1], s[2]
+	ab, ac, ao := b.sub(a), c.sub(a), a.neg()
+	if ab.cross(ac) == 0 {
+		// 三点共线,去掉c按线段处理
+		return gjkNext([]gjkVec{b, a})
+	}
+	abPerp := ab.perpToward(ac).neg() //垂直于ab,背向c
+	if abPerp.dot(ao) > 0 {
+		return gjkNext([]gjkVec{b, a})
+	}
+	acPerp := ac.perpToward(ab).neg() //垂直于ac,背向b
+	if acPerp.dot(ao) > 0 {
+		return gjkNext([]gjkVec{c, a})
+	}
+	return s, gjkVec{}, true
+}
+
+// convexIntersects 两个凸块是否相交,都是多边形时用GJK,否则用分离轴定理
+func convexIntersects(a, b *collider) bool {
+	if !a.round() && !b.round() && a.radius == 0 && b.radius == 0 {
+		if hit, ok := gjkIntersects(a.verts, b.verts); ok {
+			return hit
+		}
+	}
+	_, hit := collideConvex(a, b)
+	return hit
+}
@@ -0,0 +1,221 @@
+package geom2d
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// HashGrid 均匀哈希网格,适合大小相近、分布较均匀的形状
+// 形状加入其包围盒覆盖的每个格子,覆盖超过maxEntryCells个格子的形状单独存放,每次查询都检查
+type HashGrid struct {
+	cellSize float32
+	cells    map[gridCell][]*gridEntry
+	entries  map[IShape]*gridEntry
+	large    []*gridEntry //覆盖格子过多,不加入格子的形状
+	nextID   uint64
+	stamp    uint64 //查询去重
+}
+
+// maxEntryCells 形状最多加入的格子数
+const maxEntryCells = 256
+
+type gridCell struct{ x, y int32 }
+
+type gridEntry struct {
+	shape  IShape
+	id     uint64
+	box    box
+	x0, y0 int32 //覆盖的格子范围
+	x1, y1 int32
+	stamp  uint64
+	large  bool
+}
+
+var _ BroadPhase = (*HashGrid)(nil)
+
+// NewHashGrid cellSize一般取形状的典型大小,须为正的有限值
+func NewHashGrid(cellSize float32) *HashGrid {
+	if !(cellSize > 0) || math.IsInf(float64(cellSize), 1) {
+		panic("geom2d: HashGrid cellSize must be positive and finite")
+	}
+	return &HashGrid{cellSize: cellSize, cells: map[gridCell][]*gridEntry{}, entries: map[IShape]*gridEntry{}}
+}
+
+func (g *HashGrid) Len() int { return len(g.entries) }
+
+// cellOf 坐标所在的格子,超出int32范围时取边界;上界为MaxInt32-1,保证x<=x1的循环能结束
+func (g *HashGrid) cellOf(v float32) int32 {
+	c := math.Floor(float64(v) / float64(g.cellSize))
+	switch {
+	case c >= math.MaxInt32:
+		return math.MaxInt32 - 1
+	case c < math.MinInt32:
+		return math.MinInt32
+	case c != c: //NaN
+		return 0
+	}
+	return int32(c)
+}
+
+func (g *HashGrid) cellRange(b box) (x0, y0, x1, y1 int32) {
+	return g.cellOf(b.minX), g.cellOf(b.minY), g.cellOf(b.maxX), g.cellOf(b.maxY)
+}
+
+// coversMore 格子范围内的格子数是否超过n
+func coversMore(x0, y0, x1, y1 int32, n int) bool {
+	w, h := int64(x1)-int64(x0)+1, int64(y1)-int64(y0)+1
+	return w > int64(n) || h > int64(n) || w*h > int64(n)
+}
+
+func (g *HashGrid) link(e *gridEntry) {
+	if coversMore(e.x0, e.y0, e.x1, e.y1, maxEntryCells) {
+		e.large = true
+		g.large = append(g.large, e)
+		return
+	}
+	for x := e.x0; x <= e.x1; x++ {
+		for y := e.y0; y <= e.y1; y++ {
+			c := gridCell{x, y}
+			g.cells[c] = append(g.cells[c], e)
+		}
+	}
+}
+
+func (g *HashGrid) unlink(e *gridEntry) {
+	if e.large {
+		e.large = false
+		g.large = slices.DeleteFunc(g.large, func(o *gridEntry) bool { return o == e })
+		return
+	}
+	for x := e.x0; x <= e.x1; x++ {
+		for y := e.y0; y <= e.y1; y++ {
+			c := gridCell{x, y}
+			list := g.cells[c]
+			for i, o := range list {
+				if o == e {
+					list[i] = list[len(list)-1]
+					list = list[:len(list)-1]
+					break
+				}
+			}
+			if len(list) == 0 {
+				delete(g.cells, c)
+			} else {
+				g.cells[c] = list
+			}
+		}
+	}
+}
+
+func (g *HashGrid) Insert(s IShape) bool {
+	if _, ok := g.entries[s]; ok {
+		return false
+	}
+	b := shapeBox(s)
+	e := &gridEntry{shape: s, id: g.nextID, box: b}
+	g.nextID++
+	e.x0, e.y0, e.x1, e.y1 = g.cellRange(b)
+	g.entries[s] = e
+	g.link(e)
+	return true
+}
+
+func (g *HashGrid) Move(s IShape) bool {
+	e, ok := g.entries[s]
+	if !ok {
+		return false
+	}
+	e.box = shapeBox(s)
+	x0, y0, x1, y1 := g.cellRange(e.box)
+	if x0 != e.x0 || y0 != e.y0 || x1 != e.x1 || y1 != e.y1 {
+		g.unlink(e)
+		e.x0, e.y0, e.x1, e.y1 = x0, y0, x1, y1
+		g.link(e)
+	}
+	return true
+}
+
+func (g *HashGrid) Remove(s IShape) bool {
+	e, ok := g.entries[s]
+	if !ok {
+		return false
+	}
+	g.unlink(e)
+	delete(g.entries, s)
+	return true
+}
+
+// query 区域b覆盖的格子中满足match的形状,同一形状只返回一次
+// 覆盖的格子比已有的格子或形状还多时,直接遍历所有形状,按加入顺序返回
+func (g *HashGrid) query(b box, match func(box) bool) []IShape {
+	g.stamp++
+	var res []IShape
+	x0, y0, x1, y1 := g.cellRange(b)
+	if coversMore(x0, y0, x1, y1, min(len(g.cells), len(g.entries))) {
+		var found []*gridEntry
+		for _, e := range g.entries {
+			if match(e.box) {
+				found = append(found, e)
+			}
+		}
+		slices.SortFunc(found, func(a, b *gridEntry) int { return cmp.Compare(a.id, b.id) })
+		for _, e := range found {
+			res = append(res, e.shape)
+		}
+		return res
+	}
+	for _, e := range g.large {
+		if match(e.box) {
+			e.stamp = g.stamp
+			res = append(res, e.shape)
+		}
+	}
+	for x := x0; x <= x1; x++ {
+		for y := y0; y <= y1; y++ {
+			for _, e := range g.cells[gridCell{x, y}] {
+				if e.stamp != g.stamp && match(e.box) {
+					e.stamp = g.stamp
+					res = append(res, e.shape)
+				}
+			}
+		}
+	}
+	return res
+}
+
+func (g *HashGrid) QueryAABB(region *AABB) []IShape {
+	b := aabbBox(region)
+	return g.query(b, b.overlaps)
+}
+
+func (g *HashGrid) QueryCircle(x, y, r float32) []IShape {
+	return g.query(box{x - r, y - r, x + r, y + r}, func(b box) bool { return b.overlapsCircle(x, y, r) })
+}
+
+// Pairs 两个形状可能同在多个格子中,只在两者包围盒交集的左下角所在格子里计入
+// 单独存放的形状与所有形状逐一比较
+func (g *HashGrid) Pairs() [][2]IShape {
+	var res []candidatePair
+	for _, a := range g.large {
+		for _, b := range g.entries {
+			if b != a && (!b.large || b.id > a.id) && a.box.overlaps(b.box) {
+				res = append(res, newPair(a.shape, b.shape, a.id, b.id))
+			}
+		}
+	}
+	for c, list := range g.cells {
+		for i, a := range list {
+			for _, b := range list[i+1:] {
+				if !a.box.overlaps(b.box) {
+					continue
+				}
+				if g.cellOf(max(a.box.minX, b.box.minX)) != c.x || g.cellOf(max(a.box.minY, b.box.minY)) != c.y {
+					continue
+				}
+				res = append(res, newPair(a.shape, b.shape, a.id, b.id))
+			}
+		}
+	}
+	return sortPairs(res)
+}
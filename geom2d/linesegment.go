@@ -1,8 +1,6 @@
 package geom2d
 
 import (
-	"math"
-
 	"github.com/deminzhang/go-common/vec"
 )
 
@@ -21,29 +19,7 @@ func (ls *LineSegment) Intersects(target IShape) bool {
 	case *Circle:
 		return segmentIntersectsCircle(ls.P1.Pos, ls.P2.Pos, other.Pos, other.Radius)
 	case *Sector:
-		// endpoint inside sector
-		if pointInSectorGeneric(ls.P1.Pos, other) || pointInSectorGeneric(ls.P2.Pos, other) {
-			return true
-		}
-		// intersection with sector circle and angle check
-		if segmentIntersectsCircle(ls.P1.Pos, ls.P2.Pos, other.Pos, other.Radius) {
-			cp := closestPointOnSegment(other.Pos, ls.P1.Pos, ls.P2.Pos)
-			angle := math.Atan2(float64(cp.Y-other.Pos.Y), float64(cp.X-other.Pos.X)) * (180.0 / math.Pi)
-			if angle < 0 {
-				angle += 360.0
-			}
-			if angleBetweenDeg(float32(angle), other.StartAngle, other.EndAngle) {
-				return true
-			}
-		}
-		// radial edges intersection
-		startRad := degToRad(other.StartAngle)
-		endRad := degToRad(other.EndAngle)
-		pStart := vec.Vec2[float32]{X: other.Pos.X + float32(math.Cos(startRad))*other.Radius, Y: other.Pos.Y + float32(math.Sin(startRad))*other.Radius}
-		pEnd := vec.Vec2[float32]{X: other.Pos.X + float32(math.Cos(endRad))*other.Radius, Y: other.Pos.Y + float32(math.Sin(endRad))*other.Radius}
-		if segmentIntersectsSegment(ls.P1.Pos, ls.P2.Pos, other.Pos, pStart) || segmentIntersectsSegment(ls.P1.Pos, ls.P2.Pos, other.Pos, pEnd) {
-			return true
-		}
+		return sectorIntersectsPolygon(other, []vec.Vec2[float32]{ls.P1.Pos, ls.P2.Pos})
 	case *OBB:
 		if pointInRectangle(ls.P1.Pos, other) || pointInRectangle(ls.P2.Pos, other) {
 			return true
@@ -77,6 +53,8 @@ func (ls *LineSegment) Intersects(target IShape) bool {
 		}
 	case *LineSegment:
 		return segmentIntersectsSegment(ls.P1.Pos, ls.P2.Pos, other.P1.Pos, other.P2.Pos)
+	case *Polygon:
+		return other.Intersects(ls)
 	}
 	return false
 }
@@ -85,3 +63,21 @@ func (ls *LineSegment) Move(delta vec.Vec2[float32]) {
 	ls.P1.Pos.Add(delta)
 	ls.P2.Pos.Add(delta)
 }
+
+func (ls *LineSegment) box() box { return pointsBox(ls.P1.Pos, ls.P2.Pos) }
+
+func (ls *LineSegment) Bounds() AABB { return ls.box().aabb() }
+
+func (ls *LineSegment) Area() float32 { return 0 }
+
+func (ls *LineSegment) Centroid() vec.Vec2[float32] {
+	return vec.Vec2[float32]{X: (ls.P1.Pos.X + ls.P2.Pos.X) / 2, Y: (ls.P1.Pos.Y + ls.P2.Pos.Y) / 2}
+}
+
+func (ls *LineSegment) Contains(p vec.Vec2[float32]) bool {
+	return (&Point{BaseShape: BaseShape{Pos: p}}).isOnLineSegment(ls.P1.Pos, ls.P2.Pos)
+}
+
+func (ls *LineSegment) ClosestPoint(p vec.Vec2[float32]) vec.Vec2[float32] {
+	return closestPointOnSegment(p, ls.P1.Pos, ls.P2.Pos)
+}
@@ -1,8 +1,6 @@
 package geom2d
 
 import (
-	"math"
-
 	"github.com/deminzhang/go-common/vec"
 )
 
@@ -72,47 +70,31 @@ func (r *OBB) Intersects(target IShape) bool {
 		}
 		return false
 	case *Sector:
-		// if sector center in rect
-		if pointInRectangle(other.Pos, r) {
-			return true
-		}
-		// if any rect corner in sector
-		rC := rectangleCorners(r)
-		for _, c := range rC {
-			if pointInSectorGeneric(c, other) {
-				return true
-			}
-		}
-		// if sector arc points in rect
-		startRad := degToRad(other.StartAngle)
-		endRad := degToRad(other.EndAngle)
-		pStart := vec.Vec2[float32]{X: other.Pos.X + float32(math.Cos(startRad))*other.Radius, Y: other.Pos.Y + float32(math.Sin(startRad))*other.Radius}
-		pEnd := vec.Vec2[float32]{X: other.Pos.X + float32(math.Cos(endRad))*other.Radius, Y: other.Pos.Y + float32(math.Sin(endRad))*other.Radius}
-		if pointInRectangle(pStart, r) || pointInRectangle(pEnd, r) {
-			return true
-		}
-		// check edges vs sector radial segments and arc
-		edges3 := [][2]vec.Vec2[float32]{{rC[0], rC[1]}, {rC[1], rC[2]}, {rC[2], rC[3]}, {rC[3], rC[0]}}
-		// radial segments: check if either radial edge intersects any rect edge
-		for _, e := range edges3 {
-			if segmentIntersectsSegment(other.Pos, pStart, e[0], e[1]) || segmentIntersectsSegment(other.Pos, pEnd, e[0], e[1]) {
-				return true
-			}
-		}
-		// intersection with arc: check if any edge intersects circle and angle at closest point within sector
-		for _, e := range edges3 {
-			if segmentIntersectsCircle(e[0], e[1], other.Pos, other.Radius) {
-				cp := closestPointOnSegment(other.Pos, e[0], e[1])
-				angle := math.Atan2(float64(cp.Y-other.Pos.Y), float64(cp.X-other.Pos.X)) * (180.0 / math.Pi)
-				if angle < 0 {
-					angle += 360.0
-				}
-				if angleBetweenDeg(float32(angle), other.StartAngle, other.EndAngle) {
-					return true
-				}
-			}
-		}
-		return false
+		return sectorIntersectsPolygon(other, rectangleCorners(r))
+	case *Polygon:
+		return other.Intersects(r)
 	}
 	return false
 }
+
+func (r *OBB) box() box { return pointsBox(rectangleCorners(r)...) }
+
+func (r *OBB) Bounds() AABB { return r.box().aabb() }
+
+func (r *OBB) Area() float32 { return r.Width * r.Height }
+
+func (r *OBB) Centroid() vec.Vec2[float32] { return r.Pos }
+
+func (r *OBB) Contains(p vec.Vec2[float32]) bool { return pointInRectangle(p, r) }
+
+// ClosestPoint 转到矩形的局部坐标中夹紧再转回
+func (r *OBB) ClosestPoint(p vec.Vec2[float32]) vec.Vec2[float32] {
+	if r.Contains(p) {
+		return p
+	}
+	local := rotatePointAround(p, r.Pos, -r.Angle)
+	hw, hh := r.Width/2, r.Height/2
+	local.X = clampf(local.X, r.Pos.X-hw, r.Pos.X+hw)
+	local.Y = clampf(local.Y, r.Pos.Y-hh, r.Pos.Y+hh)
+	return rotatePointAround(local, r.Pos, r.Angle)
+}
@@ -31,6 +31,8 @@ func (p *Point) Intersects(target IShape) bool {
 		return pointInRectangle(p.Pos, &OBB{AABB: *other})
 	case *Triangle:
 		return p.intersectsTriangle(other)
+	case *Polygon:
+		return other.Intersects(p)
 	}
 	return false
 }
@@ -51,22 +53,7 @@ func (p *Point) intersectsSector(sector *Sector) bool {
 		Y: p.Pos.Y - sector.Pos.Y,
 	}
 	angle := float32(math.Atan2(float64(dir.Y), float64(dir.X)) * (180.0 / math.Pi))
-	if angle < 0 {
-		angle += 360
-	}
-	startAngle := sector.StartAngle
-	endAngle := sector.EndAngle
-	if startAngle < 0 {
-		startAngle += 360
-	}
-	if endAngle < 0 {
-		endAngle += 360
-	}
-	if startAngle > endAngle {
-		return angle >= startAngle || angle <= endAngle
-	} else {
-		return angle >= startAngle && angle <= endAngle
-	}
+	return angleBetweenDeg(angle, sector.StartAngle, sector.EndAngle)
 }
 
 func (p *Point) isOnLineSegment(start, end vec.Vec2[float32]) bool {
@@ -92,3 +79,15 @@ func (p *Point) intersectsRectangle(rect *OBB) bool {
 func (p *Point) intersectsTriangle(tri *Triangle) bool {
 	return pointInTriangle(p.Pos, tri.A.Pos, tri.B.Pos, tri.C.Pos)
 }
+
+func (p *Point) box() box { return pointsBox(p.Pos) }
+
+func (p *Point) Bounds() AABB { return p.box().aabb() }
+
+func (p *Point) Area() float32 { return 0 }
+
+func (p *Point) Centroid() vec.Vec2[float32] { return p.Pos }
+
+func (p *Point) Contains(pt vec.Vec2[float32]) bool { return p.Pos.Equal(pt) }
+
+func (p *Point) ClosestPoint(pt vec.Vec2[float32]) vec.Vec2[float32] { return p.Pos }
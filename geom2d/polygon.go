@@ -0,0 +1,360 @@
+package geom2d
+
+import (
+	"math"
+
+	"github.com/deminzhang/go-common/vec"
+)
+
+// Polygon 简单多边形,可凸可凹,顶点顺序可顺时针或逆时针,不自动闭合(末点与首点相连)
+// 凹多边形相交判定时拆成凸块,每次调用都会重新拆分,频繁判定的凹多边形可预先用ConvexDecompose拆开
+type Polygon struct {
+	Vertices []vec.Vec2[float32]
+}
+
+func NewPolygon(vertices ...vec.Vec2[float32]) *Polygon {
+	return &Polygon{Vertices: vertices}
+}
+
+func (pg *Polygon) Move(delta vec.Vec2[float32]) {
+	for i := range pg.Vertices {
+		pg.Vertices[i].Add(delta)
+	}
+}
+
+// SignedArea 有向面积,逆时针为正
+func (pg *Polygon) SignedArea() float32 {
+	return float32(signedArea(pg.Vertices))
+}
+
+func signedArea(pts []vec.Vec2[float32]) float64 {
+	var sum float64
+	for i, a := range pts {
+		b := pts[(i+1)%len(pts)]
+		sum += float64(a.X)*float64(b.Y) - float64(b.X)*float64(a.Y)
+	}
+	return sum / 2
+}
+
+// IsCCW 顶点是否按逆时针排列
+func (pg *Polygon) IsCCW() bool {
+	return signedArea(pg.Vertices) > 0
+}
+
+// Reverse 反转顶点顺序
+func (pg *Polygon) Reverse() {
+	v := pg.Vertices
+	for i, j := 0, len(v)-1; i < j; i, j = i+1, j-1 {
+		v[i], v[j] = v[j], v[i]
+	}
+}
+
+// IsConvex 是否为凸多边形,共线的顶点不影响判断;顶点少于3个或面积为0时返回false
+func (pg *Polygon) IsConvex() bool {
+	return isConvex(pg.Vertices)
+}
+
+// isConvex 各顶点转向相同且总转角为一周(排除五角星这类自交的情况)
+func isConvex(pts []vec.Vec2[float32]) bool {
+	n := len(pts)
+	if n < 3 {
+		return false
+	}
+	sign := 0
+	var turn float64
+	for i := range pts {
+		a, b, c := pts[i], pts[(i+1)%n], pts[(i+2)%n]
+		cr := cross(a, b, c)
+		if cr != 0 {
+			s := 1
+			if cr < 0 {
+				s = -1
+			}
+			if sign != 0 && s != sign {
+				return false
+			}
+			sign = s
+		}
+		ux, uy := float64(b.X-a.X), float64(b.Y-a.Y)
+		vx, vy := float64(c.X-b.X), float64(c.Y-b.Y)
+		turn += math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	}
+	return sign != 0 && math.Abs(math.Abs(turn)-2*math.Pi) < 1e-3
+}
+
+// ccwIndices 按逆时针排列的顶点下标
+func (pg *Polygon) ccwIndices() []int {
+	n := len(pg.Vertices)
+	ccw := pg.IsCCW()
+	idx := make([]int, n)
+	for i := range idx {
+		idx[i] = i
+		if !ccw {
+			idx[i] = n - 1 - i
+		}
+	}
+	return idx
+}
+
+// triangulate 耳切法,返回逆时针三角形的顶点下标;共线的顶点直接去掉
+func (pg *Polygon) triangulate() [][3]int {
+	v := pg.Vertices
+	idx := pg.ccwIndices()
+	var res [][3]int
+	for len(idx) > 3 {
+		n := len(idx)
+		// 优先切耳;自交等非简单多边形找不到耳时切去第一个凸顶点或共线顶点,保证结束
+		clip, fallback := -1, -1
+		for i := 0; i < n && clip < 0; i++ {
+			prev, cur, next := idx[(i+n-1)%n], idx[i], idx[(i+1)%n]
+			switch cr := cross(v[prev], v[cur], v[next]); {
+			case cr == 0:
+				clip = i
+			case cr > 0:
+				if fallback < 0 {
+					fallback = i
+				}
+				if pg.isEar(idx, prev, cur, next) {
+					clip = i
+				}
+			}
+		}
+		if clip < 0 {
+			clip = max(fallback, 0)
+		}
+		prev, cur, next := idx[(clip+n-1)%n], idx[clip], idx[(clip+1)%n]
+		if cross(v[prev], v[cur], v[next]) > 0 {
+			res = append(res, [3]int{prev, cur, next})
+		}
+		idx = append(idx[:clip], idx[clip+1:]...)
+	}
+	if len(idx) == 3 && cross(v[idx[0]], v[idx[1]], v[idx[2]]) > 0 {
+		res = append(res, [3]int{idx[0], idx[1], idx[2]})
+	}
+	return res
+}
+
+// isEar 三角形prev,cur,next内没有其他剩余顶点(与三角形顶点重合的除外)
+func (pg *Polygon) isEar(idx []int, prev, cur, next int) bool {
+	v := pg.Vertices
+	a, b, c := v[prev], v[cur], v[next]
+	for _, j := range idx {
+		p := v[j]
+		if j == prev || j == cur || j == next || p.Equal(a) || p.Equal(b) || p.Equal(c) {
+			continue
+		}
+		if cross(a, b, p) >= 0 && cross(b, c, p) >= 0 && cross(c, a, p) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Triangulate 耳切法三角剖分,三角形顶点按逆时针排列;面积为0的多边形返回空
+func (pg *Polygon) Triangulate() []*Triangle {
+	v := pg.Vertices
+	var res []*Triangle
+	for _, t := range pg.triangulate() {
+		a, b, c := v[t[0]], v[t[1]], v[t[2]]
+		res = append(res, NewTriangle(a.X, a.Y, b.X, b.Y, c.X, c.Y))
+	}
+	return res
+}
+
+// convexParts 凸分解(Hertel-Mehlhorn): 三角剖分后合并相邻的块,合并结果仍为凸时保留合并
+// 结果不是最少块数,但不超过最优解的4倍
+func (pg *Polygon) convexParts() [][]int {
+	if pg.IsConvex() {
+		return [][]int{pg.ccwIndices()}
+	}
+	v := pg.Vertices
+	var parts [][]int
+	for _, t := range pg.triangulate() {
+		parts = append(parts, t[:])
+	}
+	for merged := true; merged; {
+		merged = false
+		for i := 0; i < len(parts) && !merged; i++ {
+			for j := i + 1; j < len(parts) && !merged; j++ {
+				m, ok := mergeParts(parts[i], parts[j])
+				if !ok || !isConvexCCW(v, m) {
+					continue
+				}
+				parts[i] = m
+				parts = append(parts[:j], parts[j+1:]...)
+				merged = true
+			}
+		}
+	}
+	return parts
+}
+
+// mergeParts 两个逆时针的块有公共边a->b(在q中为b->a)时沿该边合并
+func mergeParts(p, q []int) ([]int, bool) {
+	for i := range p {
+		a, b := p[i], p[(i+1)%len(p)]
+		for j := range q {
+			if q[j] != b || q[(j+1)%len(q)] != a {
+				continue
+			}
+			// p从b走到a,再接q中a之后到b之前的部分
+			res := make([]int, 0, len(p)+len(q)-2)
+			for k := 0; k < len(p); k++ {
+				res = append(res, p[(i+1+k)%len(p)])
+			}
+			for k := 2; k < len(q); k++ {
+				res = append(res, q[(j+k)%len(q)])
+			}
+			return res, true
+		}
+	}
+	return nil, false
+}
+
+// isConvexCCW 逆时针的块是否凸,允许共线
+func isConvexCCW(v []vec.Vec2[float32], idx []int) bool {
+	n := len(idx)
+	for i := range idx {
+		if cross(v[idx[i]], v[idx[(i+1)%n]], v[idx[(i+2)%n]]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ConvexDecompose 拆成若干凸多边形,顶点按逆时针排列;本身为凸时返回一个副本
+func (pg *Polygon) ConvexDecompose() []*Polygon {
+	var res []*Polygon
+	for _, part := range pg.convexParts() {
+		pts := make([]vec.Vec2[float32], len(part))
+		for i, j := range part {
+			pts[i] = pg.Vertices[j]
+		}
+		res = append(res, NewPolygon(pts...))
+	}
+	return res
+}
+
+// colliders 凸块,凸分解产生的对角线记为内部边;面积为0的多边形按各条边处理
+func (pg *Polygon) colliders() []*collider {
+	v := pg.Vertices
+	switch {
+	case len(v) == 0:
+		return nil
+	case len(v) == 1:
+		return []*collider{{verts: v}}
+	}
+	var res []*collider
+	for _, part := range pg.convexParts() {
+		if len(part) < 3 {
+			continue
+		}
+		pts := make([]vec.Vec2[float32], len(part))
+		for i, j := range part {
+			pts[i] = v[j]
+		}
+		c := polygonCollider(pts)
+		for k, i := range part {
+			j := part[(k+1)%len(part)]
+			if (j-i+len(v))%len(v) == 1 || (i-j+len(v))%len(v) == 1 {
+				continue
+			}
+			if n, ok := edgeNormal(v[i], v[j]); ok {
+				c.inner = append(c.inner, n)
+			}
+		}
+		res = append(res, c)
+	}
+	if len(res) == 0 {
+		for i, a := range v {
+			b := v[(i+1)%len(v)]
+			c, _ := toCollider(NewLineSegment(a.X, a.Y, b.X, b.Y))
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// Intersects 凸块两两判定,双方都是多边形类时用GJK,带圆形部分时用分离轴定理
+func (pg *Polygon) Intersects(target IShape) bool {
+	others, ok := shapeColliders(target)
+	if !ok {
+		return false
+	}
+	for _, a := range pg.colliders() {
+		for _, b := range others {
+			if convexIntersects(a, b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (pg *Polygon) box() box {
+	if len(pg.Vertices) == 0 {
+		return box{}
+	}
+	return pointsBox(pg.Vertices...)
+}
+
+func (pg *Polygon) Bounds() AABB { return pg.box().aabb() }
+
+func (pg *Polygon) Area() float32 {
+	return float32(math.Abs(signedArea(pg.Vertices)))
+}
+
+// Centroid 面积为0时取顶点的平均
+func (pg *Polygon) Centroid() vec.Vec2[float32] {
+	v := pg.Vertices
+	if len(v) == 0 {
+		return vec.Vec2[float32]{}
+	}
+	var cx, cy, area float64
+	for i, a := range v {
+		b := v[(i+1)%len(v)]
+		w := float64(a.X)*float64(b.Y) - float64(b.X)*float64(a.Y)
+		cx += (float64(a.X) + float64(b.X)) * w
+		cy += (float64(a.Y) + float64(b.Y)) * w
+		area += w
+	}
+	if area == 0 {
+		cx, cy = 0, 0
+		for _, a := range v {
+			cx += float64(a.X)
+			cy += float64(a.Y)
+		}
+		n := float64(len(v))
+		return vec.Vec2[float32]{X: float32(cx / n), Y: float32(cy / n)}
+	}
+	return vec.Vec2[float32]{X: float32(cx / (3 * area)), Y: float32(cy / (3 * area))}
+}
+
+// Contains 射线法,边上的点算在内
+func (pg *Polygon) Contains(p vec.Vec2[float32]) bool {
+	v := pg.Vertices
+	if len(v) == 0 {
+		return false
+	}
+	if closestPointOnEdges(p, v).Equal(p) {
+		return true
+	}
+	in := false
+	for i, a := range v {
+		b := v[(i+1)%len(v)]
+		if (a.Y > p.Y) != (b.Y > p.Y) {
+			x := float64(a.X) + float64(p.Y-a.Y)*float64(b.X-a.X)/float64(b.Y-a.Y)
+			if float64(p.X) < x {
+				in = !in
+			}
+		}
+	}
+	return in
+}
+
+func (pg *Polygon) ClosestPoint(p vec.Vec2[float32]) vec.Vec2[float32] {
+	if len(pg.Vertices) == 0 || pg.Contains(p) {
+		return p
+	}
+	return closestPointOnEdges(p, pg.Vertices)
+}
@@ -0,0 +1,185 @@
+package geom2d
+
+// QuadTree 松散四叉树,节点的松散边界是其格子的2倍,形状按包围盒中心和大小放入唯一一个节点,
+// 移动时多数情况不用换节点;适合大小差异大、分布不均匀的形状
+// 中心在世界范围外的形状放在根节点
+type QuadTree struct {
+	root     *qtNode
+	maxDepth int
+	entries  map[IShape]*qtEntry
+	nextID   uint64
+}
+
+type qtNode struct {
+	cx, cy, half float32 //格子的中心和半边长,松散边界为中心±2*half
+	depth        int
+	parent       *qtNode
+	children     [4]*qtNode
+	items        []*qtEntry
+	count        int //子树中的形状数,为0时查询跳过
+}
+
+type qtEntry struct {
+	shape IShape
+	id    uint64
+	box   box
+	node  *qtNode
+}
+
+var _ BroadPhase = (*QuadTree)(nil)
+
+// NewQuadTree 世界范围为以(x,y)为中心、边长size的正方形,maxDepth为最大深度,一般取6~10
+func NewQuadTree(x, y, size float32, maxDepth int) *QuadTree {
+	return &QuadTree{root: &qtNode{cx: x, cy: y, half: size / 2}, maxDepth: maxDepth, entries: map[IShape]*qtEntry{}}
+}
+
+func (q *QuadTree) Len() int { return len(q.entries) }
+
+func (n *qtNode) looseBox() box {
+	return box{n.cx - 2*n.half, n.cy - 2*n.half, n.cx + 2*n.half, n.cy + 2*n.half}
+}
+
+// childIndex 点所在的子格子,0~3依次为左下,右下,左上,右上
+func (n *qtNode) childIndex(x, y float32) int {
+	i := 0
+	if x >= n.cx {
+		i |= 1
+	}
+	if y >= n.cy {
+		i |= 2
+	}
+	return i
+}
+
+func (n *qtNode) child(i int) *qtNode {
+	if n.children[i] == nil {
+		h := n.half / 2
+		c := &qtNode{cx: n.cx - h, cy: n.cy - h, half: h, depth: n.depth + 1, parent: n}
+		if i&1 != 0 {
+			c.cx = n.cx + h
+		}
+		if i&2 != 0 {
+			c.cy = n.cy + h
+		}
+		n.children[i] = c
+	}
+	return n.children[i]
+}
+
+// target 包围盒应放入的节点:中心所在的、半边长不小于包围盒半径的最深节点;create为false时不创建新节点,路径不存在返回nil
+func (q *QuadTree) target(b box, create bool) *qtNode {
+	n := q.root
+	cx, cy := (b.minX+b.maxX)/2, (b.minY+b.maxY)/2
+	if cx < n.cx-n.half || cx > n.cx+n.half || cy < n.cy-n.half || cy > n.cy+n.half {
+		return n
+	}
+	ext := max(b.maxX-b.minX, b.maxY-b.minY) / 2
+	// 子节点半边长为n.half/2,留10%余量防止浮点误差使包围盒超出松散边界
+	for n.depth < q.maxDepth && ext <= n.half*0.45 {
+		i := n.childIndex(cx, cy)
+		if n.children[i] == nil && !create {
+			return nil
+		}
+		n = n.child(i)
+	}
+	return n
+}
+
+func (q *QuadTree) add(e *qtEntry) {
+	n := q.target(e.box, true)
+	e.node = n
+	n.items = append(n.items, e)
+	for ; n != nil; n = n.parent {
+		n.count++
+	}
+}
+
+func (q *QuadTree) remove(e *qtEntry) {
+	n := e.node
+	for i, o := range n.items {
+		if o == e {
+			n.items[i] = n.items[len(n.items)-1]
+			n.items = n.items[:len(n.items)-1]
+			break
+		}
+	}
+	for ; n != nil; n = n.parent {
+		n.count--
+	}
+}
+
+func (q *QuadTree) Insert(s IShape) bool {
+	if _, ok := q.entries[s]; ok {
+		return false
+	}
+	b := shapeBox(s)
+	e := &qtEntry{shape: s, id: q.nextID, box: b}
+	q.nextID++
+	q.entries[s] = e
+	q.add(e)
+	return true
+}
+
+func (q *QuadTree) Move(s IShape) bool {
+	e, ok := q.entries[s]
+	if !ok {
+		return false
+	}
+	e.box = shapeBox(s)
+	if q.target(e.box, false) != e.node {
+		q.remove(e)
+		q.add(e)
+	}
+	return true
+}
+
+func (q *QuadTree) Remove(s IShape) bool {
+	e, ok := q.entries[s]
+	if !ok {
+		return false
+	}
+	q.remove(e)
+	delete(q.entries, s)
+	return true
+}
+
+func (q *QuadTree) query(n *qtNode, b box, match func(box) bool, visit func(*qtEntry)) {
+	if n == nil || n.count == 0 || (n != q.root && !n.looseBox().overlaps(b)) {
+		return
+	}
+	for _, e := range n.items {
+		if match(e.box) {
+			visit(e)
+		}
+	}
+	for _, c := range n.children {
+		q.query(c, b, match, visit)
+	}
+}
+
+func (q *QuadTree) QueryAABB(region *AABB) []IShape {
+	var res []IShape
+	b := aabbBox(region)
+	q.query(q.root, b, b.overlaps, func(e *qtEntry) { res = append(res, e.shape) })
+	return res
+}
+
+func (q *QuadTree) QueryCircle(x, y, r float32) []IShape {
+	var res []IShape
+	q.query(q.root, box{x - r, y - r, x + r, y + r}, func(b box) bool { return b.overlapsCircle(x, y, r) },
+		func(e *qtEntry) { res = append(res, e.shape) })
+	return res
+}
+
+// Pairs 松散边界互相重叠,相邻子树的形状也可能相交,所以每个形状查询一次,只计入比它后加入的形状
+func (q *QuadTree) Pairs() [][2]IShape {
+	var res []candidatePair
+	for _, a := range q.entries {
+		q.query(q.root, a.box, a.box.overlaps, func(b *qtEntry) {
+			if b.id > a.id {
+				res = append(res, candidatePair{[2]uint64{a.id, b.id}, [2]IShape{a.shape, b.shape}})
+			}
+		})
+	}
+	return sortPairs(res)
+}
@@ -0,0 +1,265 @@
+package geom2d
+
+import (
+	"math"
+
+	"github.com/deminzhang/go-common/vec"
+)
+
+// RayHit 射线或形状投射的命中信息
+type RayHit struct {
+	Point  vec.Vec2[float32] // 目标形状上的命中点
+	Normal vec.Vec2[float32] // 命中处的单位法线,朝向射线或投射形状一侧
+	Dist   float32           // 起点沿方向移动到命中时的距离
+}
+
+// Raycast 从origin沿dir发出长maxDist的射线,返回与形状的第一个交点;dir不必是单位向量
+// 起点在形状内时Dist为0,Normal为-dir
+func Raycast(origin, dir vec.Vec2[float32], maxDist float32, s IShape) (RayHit, bool) {
+	return cast(origin, dir, maxDist, s, caster{corners: []vec.Vec2[float32]{{}}})
+}
+
+// CircleCast 圆心在center、半径radius的圆沿dir移动maxDist,返回第一次接触目标形状时的信息
+// 用于高速子弹等连续碰撞检测;起点已相交时Dist为0
+func CircleCast(center vec.Vec2[float32], radius float32, dir vec.Vec2[float32], maxDist float32, s IShape) (RayHit, bool) {
+	return cast(center, dir, maxDist, s, caster{corners: []vec.Vec2[float32]{{}}, radius: radius})
+}
+
+// AABBCast 轴对齐矩形b沿dir移动maxDist,返回第一次接触目标形状时的信息;起点已相交时Dist为0
+func AABBCast(b *AABB, dir vec.Vec2[float32], maxDist float32, s IShape) (RayHit, bool) {
+	hw, hh := b.Width/2, b.Height/2
+	corners := []vec.Vec2[float32]{{X: hw, Y: hh}, {X: -hw, Y: hh}, {X: -hw, Y: -hh}, {X: hw, Y: -hh}}
+	return cast(b.Pos, dir, maxDist, s, caster{corners: corners, edges: true})
+}
+
+// NearestHit 对每个形状调用castFn,返回距离最近的命中及其形状,距离相同时取靠前的
+// 形状很多时可先用BroadPhase查询扫过区域的包围盒,只对候选调用
+//
+//	hit, s, ok := NearestHit(shapes, func(s IShape) (RayHit, bool) { return Raycast(origin, dir, 100, s) })
+func NearestHit(shapes []IShape, castFn func(IShape) (RayHit, bool)) (RayHit, IShape, bool) {
+	var best RayHit
+	var bestShape IShape
+	for _, s := range shapes {
+		if h, ok := castFn(s); ok && (bestShape == nil || h.Dist < best.Dist) {
+			best, bestShape = h, s
+		}
+	}
+	return best, bestShape, bestShape != nil
+}
+
+// caster 投射的形状,以移动的起点为中心: 顶点组成的凸多边形(射线为1个点)外扩radius
+type caster struct {
+	corners []vec.Vec2[float32]
+	edges   bool // corners围成多边形时为true
+	radius  float32
+}
+
+// overlaps 投射形状在c处是否与s相交
+func (k caster) overlaps(c vec.Vec2[float32], s IShape) bool {
+	switch {
+	case k.edges:
+		hw, hh := k.corners[0].X, k.corners[0].Y
+		return NewAABB(c.X, c.Y, 2*hw, 2*hh).Intersects(s)
+	case k.radius > 0:
+		_, hit := Collide(NewCircle(c.X, c.Y, k.radius), s)
+		return hit
+	}
+	return s.Contains(c)
+}
+
+// castArc 圆弧,sector为nil时是整圆
+type castArc struct {
+	center vec.Vec2[float32]
+	radius float32
+	sector *Sector
+}
+
+// castBoundary 形状的边界: 线段,顶点和圆弧;圆弧在坐标轴方向的极值点也作为顶点,供矩形投射用
+func castBoundary(s IShape) (segs [][2]vec.Vec2[float32], verts []vec.Vec2[float32], arcs []castArc, ok bool) {
+	polygon := func(pts []vec.Vec2[float32]) {
+		for i, a := range pts {
+			segs = append(segs, [2]vec.Vec2[float32]{a, pts[(i+1)%len(pts)]})
+		}
+		verts = pts
+	}
+	axisPoints := func(c vec.Vec2[float32], r float32, sector *Sector) {
+		for i, d := range [4][2]float32{{1, 0}, {0, 1}, {-1, 0}, {0, -1}} {
+			if sector == nil || angleBetweenDeg(float32(i*90), sector.StartAngle, sector.EndAngle) {
+				verts = append(verts, vec.Vec2[float32]{X: c.X + d[0]*r, Y: c.Y + d[1]*r})
+			}
+		}
+	}
+	switch v := s.(type) {
+	case *Point:
+		verts = []vec.Vec2[float32]{v.Pos}
+	case *Sector:
+		start, end := sectorEnds(v)
+		segs = [][2]vec.Vec2[float32]{{v.Pos, start}, {v.Pos, end}}
+		verts = []vec.Vec2[float32]{v.Pos, start, end}
+		arcs = []castArc{{v.Pos, v.Radius, v}}
+		axisPoints(v.Pos, v.Radius, v)
+	case *Circle:
+		arcs = []castArc{{v.Pos, v.Radius, nil}}
+		axisPoints(v.Pos, v.Radius, nil)
+	case *AABB:
+		polygon(rectangleCorners(&OBB{AABB: *v}))
+	case *OBB:
+		polygon(rectangleCorners(v))
+	case *Triangle:
+		polygon([]vec.Vec2[float32]{v.A.Pos, v.B.Pos, v.C.Pos})
+	case *LineSegment:
+		segs = [][2]vec.Vec2[float32]{{v.P1.Pos, v.P2.Pos}}
+		verts = []vec.Vec2[float32]{v.P1.Pos, v.P2.Pos}
+	case *Polygon:
+		if len(v.Vertices) == 0 {
+			return nil, nil, nil, false
+		}
+		polygon(v.Vertices)
+	default:
+		return nil, nil, nil, false
+	}
+	return segs, verts, arcs, true
+}
+
+// cast 投射形状k沿射线移动时与s的第一次接触,即射线与s和k的闵可夫斯基和的第一个交点
+// 和的边界由以下几部分组成,全部求交取最近的:
+// s的边平移k的顶点再沿法线外扩radius, s的顶点处的圆(半径radius)和平移到s顶点的k的边,
+// s的圆弧平移k的顶点后半径加radius
+func cast(origin, dir vec.Vec2[float32], maxDist float32, s IShape, k caster) (RayHit, bool) {
+	d, ok := unitVec(float64(dir.X), float64(dir.Y))
+	if !ok || maxDist < 0 {
+		return RayHit{}, false
+	}
+	segs, verts, arcs, ok := castBoundary(s)
+	if !ok {
+		return RayHit{}, false
+	}
+	if k.overlaps(origin, s) {
+		return RayHit{Point: s.ClosestPoint(origin), Normal: vec.Vec2[float32]{X: -d.X, Y: -d.Y}}, true
+	}
+	r := ray{origin, d}
+	best := rayResult{t: float64(maxDist)}
+	found := false
+	try := func(res rayResult, ok bool) {
+		if ok && res.t <= best.t {
+			best, found = res, true
+		}
+	}
+	offset := func(p vec.Vec2[float32], dx, dy float32) vec.Vec2[float32] {
+		return vec.Vec2[float32]{X: p.X + dx, Y: p.Y + dy}
+	}
+	for _, seg := range segs {
+		n, ok := edgeNormal(seg[0], seg[1])
+		if !ok {
+			continue
+		}
+		for _, c := range k.corners {
+			for _, side := range [2]float32{1, -1} {
+				ox, oy := c.X+side*n.X*k.radius, c.Y+side*n.Y*k.radius
+				res, ok := r.segment(offset(seg[0], ox, oy), offset(seg[1], ox, oy))
+				res.point = offset(res.point, -ox, -oy)
+				try(res, ok)
+				if k.radius == 0 {
+					break
+				}
+			}
+		}
+	}
+	for _, v := range verts {
+		res, ok := r.circle(v, k.radius, nil)
+		res.point = v
+		try(res, ok)
+		if !k.edges {
+			continue
+		}
+		for i, c := range k.corners {
+			e := k.corners[(i+1)%len(k.corners)]
+			res, ok := r.segment(offset(v, c.X, c.Y), offset(v, e.X, e.Y))
+			res.point = v
+			try(res, ok)
+		}
+	}
+	for _, a := range arcs {
+		for _, c := range k.corners {
+			res, ok := r.circle(offset(a.center, c.X, c.Y), a.radius+k.radius, a.sector)
+			res.point = offset(res.point, -c.X-res.normal.X*k.radius, -c.Y-res.normal.Y*k.radius)
+			try(res, ok)
+		}
+	}
+	if !found {
+		return RayHit{}, false
+	}
+	return RayHit{Point: best.point, Normal: best.normal, Dist: float32(best.t)}, true
+}
+
+// ray 起点o,单位方向d
+type ray struct {
+	o, d vec.Vec2[float32]
+}
+
+type rayResult struct {
+	t      float64
+	point  vec.Vec2[float32]
+	normal vec.Vec2[float32]
+}
+
+func (r ray) at(t float64) vec.Vec2[float32] {
+	return vec.Vec2[float32]{X: float32(float64(r.o.X) + t*float64(r.d.X)), Y: float32(float64(r.o.Y) + t*float64(r.d.Y))}
+}
+
+// segment 与线段ab的第一个交点,法线朝向射线来的一侧;共线时取较近的端点,法线为-d
+func (r ray) segment(a, b vec.Vec2[float32]) (rayResult, bool) {
+	ex, ey := float64(b.X-a.X), float64(b.Y-a.Y)
+	dx, dy := float64(r.d.X), float64(r.d.Y)
+	ax, ay := float64(a.X-r.o.X), float64(a.Y-r.o.Y)
+	den := dx*ey - dy*ex
+	if den == 0 {
+		if ax*dy-ay*dx != 0 {
+			return rayResult{}, false //平行不共线
+		}
+		t0, t1 := ax*dx+ay*dy, float64(b.X-r.o.X)*dx+float64(b.Y-r.o.Y)*dy
+		t := math.Min(t0, t1)
+		if math.Max(t0, t1) < 0 {
+			return rayResult{}, false
+		}
+		t = math.Max(t, 0)
+		return rayResult{t: t, point: r.at(t), normal: vec.Vec2[float32]{X: -r.d.X, Y: -r.d.Y}}, true
+	}
+	t := (ax*ey - ay*ex) / den
+	u := (ax*dy - ay*dx) / den
+	if t < 0 || u < 0 || u > 1 {
+		return rayResult{}, false
+	}
+	n, _ := edgeNormal(a, b)
+	if dot(n, r.d) > 0 {
+		n = vec.Vec2[float32]{X: -n.X, Y: -n.Y}
+	}
+	return rayResult{t: t, point: r.at(t), normal: n}, true
+}
+
+// circle 与圆心c半径radius的圆周的第一个交点,sector非nil时交点须在其角度范围内;法线沿半径向外
+func (r ray) circle(c vec.Vec2[float32], radius float32, sector *Sector) (rayResult, bool) {
+	fx, fy := float64(r.o.X-c.X), float64(r.o.Y-c.Y)
+	b := fx*float64(r.d.X) + fy*float64(r.d.Y)
+	cc := fx*fx + fy*fy - float64(radius)*float64(radius)
+	disc := b*b - cc
+	if disc < 0 {
+		return rayResult{}, false
+	}
+	sq := math.Sqrt(disc)
+	for _, t := range [2]float64{-b - sq, -b + sq} {
+		if t < 0 {
+			continue
+		}
+		px, py := fx+t*float64(r.d.X), fy+t*float64(r.d.Y)
+		if sector != nil && !angleBetweenDeg(float32(math.Atan2(py, px)*180/math.Pi), sector.StartAngle, sector.EndAngle) {
+			continue
+		}
+		n, ok := unitVec(px, py)
+		if !ok {
+			n = vec.Vec2[float32]{X: -r.d.X, Y: -r.d.Y}
+		}
+		return rayResult{t: t, point: r.at(t), normal: n}, true
+	}
+	return rayResult{}, false
+}
@@ -32,6 +32,8 @@ func (s *Sector) Intersects(target IShape) bool {
 		return other.Intersects(s)
 	case *Triangle:
 		return other.Intersects(s)
+	case *Polygon:
+		return other.Intersects(s)
 	}
 	return false
 }
@@ -40,6 +42,7 @@ func (s *Sector) intersectsCircle(circle *Circle) bool {
 	return circle.intersectsSector(s)
 }
 
+// intersectsSector 一方的半径与另一方相交(含圆心在另一方内),或两段圆弧相交
 func (s *Sector) intersectsSector(other *Sector) bool {
 	// quick reject by circle distances
 	dx := float64(s.Pos.X - other.Pos.X)
@@ -48,38 +51,72 @@ func (s *Sector) intersectsSector(other *Sector) bool {
 	if d > float64(s.Radius+other.Radius) {
 		return false
 	}
-	// center containment
-	if pointInSectorGeneric(s.Pos, other) || pointInSectorGeneric(other.Pos, s) {
-		return true
-	}
-	// check endpoints of s
-	startRad := degToRad(s.StartAngle)
-	endRad := degToRad(s.EndAngle)
-	pStart := vec.Vec2[float32]{X: s.Pos.X + float32(math.Cos(startRad))*s.Radius, Y: s.Pos.Y + float32(math.Sin(startRad))*s.Radius}
-	pEnd := vec.Vec2[float32]{X: s.Pos.X + float32(math.Cos(endRad))*s.Radius, Y: s.Pos.Y + float32(math.Sin(endRad))*s.Radius}
-	if pointInSectorGeneric(pStart, other) || pointInSectorGeneric(pEnd, other) {
-		return true
-	}
-	// check endpoints of other
-	ostart := degToRad(other.StartAngle)
-	oend := degToRad(other.EndAngle)
-	oStart := vec.Vec2[float32]{X: other.Pos.X + float32(math.Cos(ostart))*other.Radius, Y: other.Pos.Y + float32(math.Sin(ostart))*other.Radius}
-	oEnd := vec.Vec2[float32]{X: other.Pos.X + float32(math.Cos(oend))*other.Radius, Y: other.Pos.Y + float32(math.Sin(oend))*other.Radius}
-	if pointInSectorGeneric(oStart, s) || pointInSectorGeneric(oEnd, s) {
+	sStart, sEnd := sectorEnds(s)
+	oStart, oEnd := sectorEnds(other)
+	if sectorIntersectsPolygon(other, []vec.Vec2[float32]{s.Pos, sStart}) ||
+		sectorIntersectsPolygon(other, []vec.Vec2[float32]{s.Pos, sEnd}) ||
+		sectorIntersectsPolygon(s, []vec.Vec2[float32]{other.Pos, oStart}) ||
+		sectorIntersectsPolygon(s, []vec.Vec2[float32]{other.Pos, oEnd}) {
 		return true
 	}
 	// check circle-circle intersection points
 	pts, ok := circleCircleIntersections(s.Pos, other.Pos, s.Radius, other.Radius)
 	if ok {
 		for _, p := range pts {
-			if pointInSectorGeneric(p, s) && pointInSectorGeneric(p, other) {
+			if angleInSector(p, s) && angleInSector(p, other) {
 				return true
 			}
 		}
 	}
-	// check radial edges vs other circle
-	if segmentIntersectsCircle(s.Pos, pStart, other.Pos, other.Radius) || segmentIntersectsCircle(s.Pos, pEnd, other.Pos, other.Radius) || segmentIntersectsCircle(other.Pos, oStart, s.Pos, s.Radius) || segmentIntersectsCircle(other.Pos, oEnd, s.Pos, s.Radius) {
-		return true
-	}
 	return false
 }
+
+// box 圆心,两条半径的外端点,以及圆弧经过的坐标轴方向的极值点
+func (s *Sector) box() box {
+	start, end := sectorEnds(s)
+	b := pointsBox(s.Pos, start, end)
+	for i, d := range [4][2]float32{{1, 0}, {0, 1}, {-1, 0}, {0, -1}} {
+		if angleBetweenDeg(float32(i*90), s.StartAngle, s.EndAngle) {
+			b = b.union(pointsBox(vec.Vec2[float32]{X: s.Pos.X + d[0]*s.Radius, Y: s.Pos.Y + d[1]*s.Radius}))
+		}
+	}
+	return b
+}
+
+func (s *Sector) Bounds() AABB { return s.box().aabb() }
+
+func (s *Sector) Area() float32 {
+	return float32(degToRad(float32(sectorSpanDeg(s))) * float64(s.Radius) * float64(s.Radius) / 2)
+}
+
+// Centroid 在角平分线上,距圆心4r·sin(θ/2)/(3θ),θ为张角
+func (s *Sector) Centroid() vec.Vec2[float32] {
+	span := sectorSpanDeg(s)
+	theta := degToRad(float32(span))
+	d := 2.0 / 3 * float64(s.Radius)
+	if theta > 0 {
+		d = 4 * float64(s.Radius) * math.Sin(theta/2) / (3 * theta)
+	}
+	mid := degToRad(s.StartAngle) + theta/2
+	return vec.Vec2[float32]{X: s.Pos.X + float32(math.Cos(mid)*d), Y: s.Pos.Y + float32(math.Sin(mid)*d)}
+}
+
+func (s *Sector) Contains(p vec.Vec2[float32]) bool { return pointInSectorGeneric(p, s) }
+
+// ClosestPoint p在扇形外时取两条半径和圆弧上的最近点中最近的
+func (s *Sector) ClosestPoint(p vec.Vec2[float32]) vec.Vec2[float32] {
+	if s.Contains(p) {
+		return p
+	}
+	start, end := sectorEnds(s)
+	best := closestPointOnSegment(p, s.Pos, start)
+	if q := closestPointOnSegment(p, s.Pos, end); q.DistanceSqr(p) < best.DistanceSqr(p) {
+		best = q
+	}
+	if !p.Equal(s.Pos) && angleInSector(p, s) {
+		if q := pointOnCircle(s.Pos, s.Radius, p); q.DistanceSqr(p) < best.DistanceSqr(p) {
+			best = q
+		}
+	}
+	return best
+}
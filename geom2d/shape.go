@@ -4,6 +4,16 @@ import "github.com/deminzhang/go-common/vec"
 
 type IShape interface {
 	Intersects(other IShape) bool
+	// Bounds 轴对齐包围盒
+	Bounds() AABB
+	// Area 面积,点和线段为0
+	Area() float32
+	// Centroid 质心
+	Centroid() vec.Vec2[float32]
+	// Contains 点是否在形状内,边界上算在内
+	Contains(p vec.Vec2[float32]) bool
+	// ClosestPoint 形状上离p最近的点,p在形状内时返回p
+	ClosestPoint(p vec.Vec2[float32]) vec.Vec2[float32]
 }
 
 type BaseShape struct {
@@ -67,35 +67,36 @@ func (t *Triangle) Intersects(target IShape) bool {
 		}
 		return false
 	case *Sector:
-		// any triangle vertex in sector
-		if pointInSectorGeneric(t.A.Pos, other) || pointInSectorGeneric(t.B.Pos, other) || pointInSectorGeneric(t.C.Pos, other) {
-			return true
-		}
-		// any sector endpoints inside triangle
-		startRad := degToRad(other.StartAngle)
-		endRad := degToRad(other.EndAngle)
-		pStart := vec.Vec2[float32]{X: other.Pos.X + float32(math.Cos(startRad))*other.Radius, Y: other.Pos.Y + float32(math.Sin(startRad))*other.Radius}
-		pEnd := vec.Vec2[float32]{X: other.Pos.X + float32(math.Cos(endRad))*other.Radius, Y: other.Pos.Y + float32(math.Sin(endRad))*other.Radius}
-		if pointInTriangle(pStart, t.A.Pos, t.B.Pos, t.C.Pos) || pointInTriangle(pEnd, t.A.Pos, t.B.Pos, t.C.Pos) {
-			return true
-		}
-		// edges intersection with radial segments or arc
-		if segmentIntersectsSegment(t.A.Pos, t.B.Pos, other.Pos, pStart) || segmentIntersectsSegment(t.B.Pos, t.C.Pos, other.Pos, pStart) || segmentIntersectsSegment(t.C.Pos, t.A.Pos, other.Pos, pStart) {
-			return true
-		}
-		if segmentIntersectsCircle(t.A.Pos, t.B.Pos, other.Pos, other.Radius) || segmentIntersectsCircle(t.B.Pos, t.C.Pos, other.Pos, other.Radius) || segmentIntersectsCircle(t.C.Pos, t.A.Pos, other.Pos, other.Radius) {
-			// need to check angle of closest point
-			if cp := closestPointOnSegment(other.Pos, t.A.Pos, t.B.Pos); angleBetweenDeg(float32(math.Atan2(float64(cp.Y-other.Pos.Y), float64(cp.X-other.Pos.X))*(180.0/math.Pi)), other.StartAngle, other.EndAngle) {
-				return true
-			}
-			if cp := closestPointOnSegment(other.Pos, t.B.Pos, t.C.Pos); angleBetweenDeg(float32(math.Atan2(float64(cp.Y-other.Pos.Y), float64(cp.X-other.Pos.X))*(180.0/math.Pi)), other.StartAngle, other.EndAngle) {
-				return true
-			}
-			if cp := closestPointOnSegment(other.Pos, t.C.Pos, t.A.Pos); angleBetweenDeg(float32(math.Atan2(float64(cp.Y-other.Pos.Y), float64(cp.X-other.Pos.X))*(180.0/math.Pi)), other.StartAngle, other.EndAngle) {
-				return true
-			}
-		}
-		return false
+		return sectorIntersectsPolygon(other, []vec.Vec2[float32]{t.A.Pos, t.B.Pos, t.C.Pos})
+	case *Polygon:
+		return other.Intersects(t)
 	}
 	return false
 }
+
+func (t *Triangle) box() box { return pointsBox(t.A.Pos, t.B.Pos, t.C.Pos) }
+
+func (t *Triangle) Bounds() AABB { return t.box().aabb() }
+
+func (t *Triangle) Area() float32 {
+	return float32(math.Abs(cross(t.A.Pos, t.B.Pos, t.C.Pos)) / 2)
+}
+
+func (t *Triangle) Centroid() vec.Vec2[float32] {
+	return vec.Vec2[float32]{X: (t.A.Pos.X + t.B.Pos.X + t.C.Pos.X) / 3, Y: (t.A.Pos.Y + t.B.Pos.Y + t.C.Pos.Y) / 3}
+}
+
+// Contains 退化为线段的三角形按三条边判断
+func (t *Triangle) Contains(p vec.Vec2[float32]) bool {
+	if cross(t.A.Pos, t.B.Pos, t.C.Pos) == 0 {
+		return closestPointOnEdges(p, []vec.Vec2[float32]{t.A.Pos, t.B.Pos, t.C.Pos}).Equal(p)
+	}
+	return pointInTriangle(p, t.A.Pos, t.B.Pos, t.C.Pos)
+}
+
+func (t *Triangle) ClosestPoint(p vec.Vec2[float32]) vec.Vec2[float32] {
+	if t.Contains(p) {
+		return p
+	}
+	return closestPointOnEdges(p, []vec.Vec2[float32]{t.A.Pos, t.B.Pos, t.C.Pos})
+}
@@ -280,3 +280,111 @@ func circleCircleIntersections(c1, c2 vec.Vec2[float32], r1, r2 float32) ([]vec.
 	}
 	return []vec.Vec2[float32]{p1, p2}, true
 }
+
+// sectorEnds 扇形两条半径的外端点
+func sectorEnds(s *Sector) (start, end vec.Vec2[float32]) {
+	startRad := degToRad(s.StartAngle)
+	endRad := degToRad(s.EndAngle)
+	start = vec.Vec2[float32]{X: s.Pos.X + float32(math.Cos(startRad))*s.Radius, Y: s.Pos.Y + float32(math.Sin(startRad))*s.Radius}
+	end = vec.Vec2[float32]{X: s.Pos.X + float32(math.Cos(endRad))*s.Radius, Y: s.Pos.Y + float32(math.Sin(endRad))*s.Radius}
+	return start, end
+}
+
+// segmentIntersectsArc 线段是否与扇形的圆弧相交
+func segmentIntersectsArc(a, b vec.Vec2[float32], s *Sector) bool {
+	dx := float64(b.X - a.X)
+	dy := float64(b.Y - a.Y)
+	dd := dx*dx + dy*dy
+	if dd == 0 {
+		return false
+	}
+	// 圆心在直线上的投影参数t0,半弦长对应的参数dt
+	fx := float64(s.Pos.X - a.X)
+	fy := float64(s.Pos.Y - a.Y)
+	t0 := (fx*dx + fy*dy) / dd
+	qx, qy := dx*t0-fx, dy*t0-fy
+	h2 := float64(s.Radius)*float64(s.Radius) - (qx*qx + qy*qy)
+	if h2 < 0 {
+		return false
+	}
+	dt := math.Sqrt(h2 / dd)
+	for _, t := range [2]float64{t0 - dt, t0 + dt} {
+		if t < 0 || t > 1 {
+			continue
+		}
+		angle := math.Atan2(dy*t-fy, dx*t-fx) * (180.0 / math.Pi)
+		if angleBetweenDeg(float32(angle), s.StartAngle, s.EndAngle) {
+			return true
+		}
+	}
+	return false
+}
+
+// sectorIntersectsPolygon 扇形与凸多边形(2个点时为线段)是否相交:
+// 顶点在扇形内,扇形圆心在多边形内,或边与扇形的半径、圆弧相交
+func sectorIntersectsPolygon(s *Sector, pts []vec.Vec2[float32]) bool {
+	for _, p := range pts {
+		if pointInSectorGeneric(p, s) {
+			return true
+		}
+	}
+	if len(pts) == 3 && pointInTriangle(s.Pos, pts[0], pts[1], pts[2]) {
+		return true
+	}
+	if len(pts) == 4 && (pointInTriangle(s.Pos, pts[0], pts[1], pts[2]) || pointInTriangle(s.Pos, pts[0], pts[2], pts[3])) {
+		return true
+	}
+	pStart, pEnd := sectorEnds(s)
+	n := len(pts)
+	if n == 2 {
+		n = 1
+	}
+	for i := 0; i < n; i++ {
+		a, b := pts[i], pts[(i+1)%len(pts)]
+		if segmentIntersectsSegment(a, b, s.Pos, pStart) || segmentIntersectsSegment(a, b, s.Pos, pEnd) ||
+			segmentIntersectsArc(a, b, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// sectorSpanDeg 扇形从StartAngle逆时针到EndAngle的角度,[0,360)
+func sectorSpanDeg(s *Sector) float64 {
+	span := normalizeAngleDeg(float64(s.EndAngle)) - normalizeAngleDeg(float64(s.StartAngle))
+	if span < 0 {
+		span += 360
+	}
+	return span
+}
+
+// angleInSector p相对扇形圆心的方向是否在扇形的角度范围内
+func angleInSector(p vec.Vec2[float32], s *Sector) bool {
+	angle := math.Atan2(float64(p.Y-s.Pos.Y), float64(p.X-s.Pos.X)) * (180.0 / math.Pi)
+	return angleBetweenDeg(float32(angle), s.StartAngle, s.EndAngle)
+}
+
+// cross 向量ab与ac的叉积
+func cross(a, b, c vec.Vec2[float32]) float64 {
+	return float64(b.X-a.X)*float64(c.Y-a.Y) - float64(b.Y-a.Y)*float64(c.X-a.X)
+}
+
+// pointOnCircle 圆上朝向p的点,p为圆心时取x正方向
+func pointOnCircle(center vec.Vec2[float32], r float32, p vec.Vec2[float32]) vec.Vec2[float32] {
+	d, ok := unitVec(float64(p.X-center.X), float64(p.Y-center.Y))
+	if !ok {
+		d = vec.Vec2[float32]{X: 1}
+	}
+	return vec.Vec2[float32]{X: center.X + d.X*r, Y: center.Y + d.Y*r}
+}
+
+// closestPointOnEdges 多边形各边上离p最近的点
+func closestPointOnEdges(p vec.Vec2[float32], pts []vec.Vec2[float32]) vec.Vec2[float32] {
+	best := pts[0]
+	for i, a := range pts {
+		if q := closestPointOnSegment(p, a, pts[(i+1)%len(pts)]); q.DistanceSqr(p) < best.DistanceSqr(p) {
+			best = q
+		}
+	}
+	return best
+}
@@ -0,0 +1,31 @@
+package geom3d
+
+import (
+	"github.com/deminzhang/go-common/fix64"
+	"github.com/deminzhang/go-common/vec"
+)
+
+// FixAABB 轴对齐长方体,Pos为中心
+type FixAABB struct {
+	FixBaseShape
+	Size vec.FixVector3 // 各轴上的边长
+}
+
+func NewFixAABB(x, y, z, sizeX, sizeY, sizeZ fix64.Fix64) *FixAABB {
+	return &FixAABB{FixBaseShape: FixBaseShape{Pos: vec.FixVector3{X: x, Y: y, Z: z}}, Size: vec.FixVector3{X: sizeX, Y: sizeY, Z: sizeZ}}
+}
+
+func (a *FixAABB) Intersects(target IFixShape) bool {
+	switch other := target.(type) {
+	case *FixSphere:
+		return other.Intersects(a)
+	case *FixPoint, *FixLineSegment, *FixTriangle, *FixAABB, *FixOBB:
+		// 按不旋转的OBB处理
+		return a.obb().Intersects(other)
+	}
+	return false
+}
+
+func (a *FixAABB) obb() *FixOBB {
+	return &FixOBB{FixAABB: *a, Rotation: vec.IdentityFQ()}
+}
@@ -0,0 +1,41 @@
+package geom3d
+
+import (
+	"github.com/deminzhang/go-common/fix64"
+	"github.com/deminzhang/go-common/vec"
+)
+
+type FixLineSegment struct {
+	P1, P2 FixPoint
+}
+
+func NewFixLineSegment(x1, y1, z1, x2, y2, z2 fix64.Fix64) *FixLineSegment {
+	return &FixLineSegment{P1: *NewFixPoint(x1, y1, z1), P2: *NewFixPoint(x2, y2, z2)}
+}
+
+func (ls *FixLineSegment) points() []vec.FixVector3 {
+	return []vec.FixVector3{ls.P1.Pos, ls.P2.Pos}
+}
+
+func (ls *FixLineSegment) Intersects(target IFixShape) bool {
+	switch other := target.(type) {
+	case *FixPoint:
+		return other.Intersects(ls)
+	case *FixSphere:
+		return other.Intersects(ls)
+	case *FixOBB:
+		return other.Intersects(ls)
+	case *FixAABB:
+		return other.Intersects(ls)
+	case *FixTriangle:
+		return fixTriangleIntersectsPoints(other.points(), ls.points())
+	case *FixLineSegment:
+		return fixSegmentIntersectsSegment(ls.P1.Pos, ls.P2.Pos, other.P1.Pos, other.P2.Pos)
+	}
+	return false
+}
+
+func (ls *FixLineSegment) Move(delta vec.FixVector3) {
+	ls.P1.Pos.Add(delta)
+	ls.P2.Pos.Add(delta)
+}
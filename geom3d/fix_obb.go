@@ -0,0 +1,45 @@
+package geom3d
+
+import (
+	"github.com/deminzhang/go-common/fix64"
+	"github.com/deminzhang/go-common/vec"
+)
+
+// FixOBB 有向长方体,Pos为中心,局部坐标各轴上的边长为Size
+type FixOBB struct {
+	FixAABB
+	Rotation vec.FixQuaternion // 需为单位四元数
+}
+
+func NewFixOBB(x, y, z, sizeX, sizeY, sizeZ fix64.Fix64, rotation vec.FixQuaternion) *FixOBB {
+	return &FixOBB{FixAABB: *NewFixAABB(x, y, z, sizeX, sizeY, sizeZ), Rotation: rotation}
+}
+
+func (r *FixOBB) Intersects(target IFixShape) bool {
+	switch other := target.(type) {
+	case *FixPoint:
+		return pointInFixBox(other.Pos, r)
+	case *FixSphere:
+		return other.intersectsBox(r)
+	case *FixOBB:
+		// 分离轴为两者的各3条轴和轴两两叉乘
+		axes := fixBoxAxes(r)
+		otherAxes := fixBoxAxes(other)
+		all := append(append(axes, otherAxes...), fixCrossAxes(axes, otherAxes)...)
+		return fixOverlapOnAxes(fixBoxCorners(r), fixBoxCorners(other), all...)
+	case *FixAABB:
+		return r.Intersects(other.obb())
+	case *FixLineSegment:
+		pts := other.points()
+		return fixBoxIntersectsPoints(r, pts, fixEdges(pts), vec.FixVector3{})
+	case *FixTriangle:
+		pts := other.points()
+		return fixBoxIntersectsPoints(r, pts, fixEdges(pts), fixTriangleNormal(pts[0], pts[1], pts[2]))
+	}
+	return false
+}
+
+// Corners 八个顶点
+func (r *FixOBB) Corners() []vec.FixVector3 {
+	return fixBoxCorners(r)
+}
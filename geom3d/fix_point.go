@@ -0,0 +1,32 @@
+package geom3d
+
+import (
+	"github.com/deminzhang/go-common/fix64"
+	"github.com/deminzhang/go-common/vec"
+)
+
+type FixPoint struct {
+	FixBaseShape
+}
+
+func NewFixPoint(x, y, z fix64.Fix64) *FixPoint {
+	return &FixPoint{FixBaseShape: FixBaseShape{Pos: vec.FixVector3{X: x, Y: y, Z: z}}}
+}
+
+func (p *FixPoint) Intersects(target IFixShape) bool {
+	switch other := target.(type) {
+	case *FixPoint:
+		return p.Pos.Equal(other.Pos)
+	case *FixSphere:
+		return other.contains(p.Pos)
+	case *FixLineSegment:
+		return pointOnFixSegment(p.Pos, other.P1.Pos, other.P2.Pos)
+	case *FixOBB:
+		return pointInFixBox(p.Pos, other)
+	case *FixAABB:
+		return pointInFixBox(p.Pos, other.obb())
+	case *FixTriangle:
+		return fixTriangleIntersectsPoints(other.points(), []vec.FixVector3{p.Pos})
+	}
+	return false
+}
@@ -0,0 +1,20 @@
+package geom3d
+
+import "github.com/deminzhang/go-common/vec"
+
+// IFixShape 定点数3D图形:点,球,AABB,OBB,线段,三角形,两两之间都可判断相交,边界接触也算相交
+type IFixShape interface {
+	Intersects(other IFixShape) bool
+}
+
+type FixBaseShape struct {
+	Pos vec.FixVector3
+}
+
+func (bs *FixBaseShape) Intersects(other IFixShape) bool {
+	return false
+}
+
+func (bs *FixBaseShape) Move(delta vec.FixVector3) {
+	bs.Pos.Add(delta)
+}
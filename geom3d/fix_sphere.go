@@ -0,0 +1,45 @@
+package geom3d
+
+import (
+	"github.com/deminzhang/go-common/fix64"
+	"github.com/deminzhang/go-common/vec"
+)
+
+type FixSphere struct {
+	FixBaseShape
+	Radius fix64.Fix64
+}
+
+func NewFixSphere(x, y, z, r fix64.Fix64) *FixSphere {
+	return &FixSphere{FixBaseShape: FixBaseShape{Pos: vec.FixVector3{X: x, Y: y, Z: z}}, Radius: r}
+}
+
+func (s *FixSphere) Intersects(target IFixShape) bool {
+	switch other := target.(type) {
+	case *FixPoint:
+		return other.Intersects(s)
+	case *FixSphere:
+		r := s.Radius + other.Radius
+		return fixDistSq(s.Pos, other.Pos) <= r.Mul(r)
+	case *FixAABB:
+		return s.intersectsBox(other.obb())
+	case *FixOBB:
+		return s.intersectsBox(other)
+	case *FixLineSegment:
+		return s.contains(fixClosestPointOnSegment(s.Pos, other.P1.Pos, other.P2.Pos))
+	case *FixTriangle:
+		return s.contains(fixClosestPointOnTriangle(s.Pos, other.A.Pos, other.B.Pos, other.C.Pos))
+	}
+	return false
+}
+
+// contains p是否在球内(含球面)
+func (s *FixSphere) contains(p vec.FixVector3) bool {
+	return fixDistSq(s.Pos, p) <= s.Radius.Mul(s.Radius)
+}
+
+func (s *FixSphere) intersectsBox(r *FixOBB) bool {
+	// 球心转到长方体局部坐标,求最近点
+	local := fixToLocal(s.Pos, r)
+	return fixDistSq(local, fixClampToBox(local, fixHalfSize(r))) <= s.Radius.Mul(s.Radius)
+}
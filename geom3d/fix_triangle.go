@@ -0,0 +1,47 @@
+package geom3d
+
+import (
+	"github.com/deminzhang/go-common/fix64"
+	"github.com/deminzhang/go-common/vec"
+)
+
+type FixTriangle struct {
+	A, B, C FixPoint
+}
+
+func NewFixTriangle(ax, ay, az, bx, by, bz, cx, cy, cz fix64.Fix64) *FixTriangle {
+	return &FixTriangle{A: *NewFixPoint(ax, ay, az), B: *NewFixPoint(bx, by, bz), C: *NewFixPoint(cx, cy, cz)}
+}
+
+func (t *FixTriangle) points() []vec.FixVector3 {
+	return []vec.FixVector3{t.A.Pos, t.B.Pos, t.C.Pos}
+}
+
+func (t *FixTriangle) Intersects(target IFixShape) bool {
+	switch other := target.(type) {
+	case *FixPoint:
+		return other.Intersects(t)
+	case *FixSphere:
+		return other.Intersects(t)
+	case *FixOBB:
+		return other.Intersects(t)
+	case *FixAABB:
+		return other.Intersects(t)
+	case *FixLineSegment:
+		return other.Intersects(t)
+	case *FixTriangle:
+		return fixTriangleIntersectsPoints(t.points(), other.points())
+	}
+	return false
+}
+
+// Normal 单位法线,A,B,C逆时针看时朝向观察者;三点共线时为0
+func (t *FixTriangle) Normal() vec.FixVector3 {
+	return fixTriangleNormal(t.A.Pos, t.B.Pos, t.C.Pos)
+}
+
+func (t *FixTriangle) Move(delta vec.FixVector3) {
+	t.A.Pos.Add(delta)
+	t.B.Pos.Add(delta)
+	t.C.Pos.Add(delta)
+}
@@ -0,0 +1,223 @@
+package geom3d
+
+import (
+	"github.com/deminzhang/go-common/fix64"
+	"github.com/deminzhang/go-common/vec"
+)
+
+// 定点数版本的3D几何运算,只用整数运算,各平台结果一致,用于帧同步的命中判定
+// 平方距离在Fix64范围内,坐标绝对值需小于约2.6万;分离轴都先单位化,投影不超过坐标的量级
+
+var (
+	fixUnitX = vec.FixVector3{X: fix64.FixOne}
+	fixUnitY = vec.FixVector3{Y: fix64.FixOne}
+	fixUnitZ = vec.FixVector3{Z: fix64.FixOne}
+)
+
+func fixDot(u, v vec.FixVector3) fix64.Fix64 {
+	return u.X.Mul(v.X) + u.Y.Mul(v.Y) + u.Z.Mul(v.Z)
+}
+
+func fixClamp(v, lo, hi fix64.Fix64) fix64.Fix64 {
+	return min(max(v, lo), hi)
+}
+
+func fixIsZero(v vec.FixVector3) bool {
+	return v.X == 0 && v.Y == 0 && v.Z == 0
+}
+
+// fixDistSq 两点距离的平方
+func fixDistSq(a, b vec.FixVector3) fix64.Fix64 {
+	d := vec.SubFV3(a, b)
+	return fixDot(d, d)
+}
+
+// fixLerp a+(b-a)*t
+func fixLerp(a, b vec.FixVector3, t fix64.Fix64) vec.FixVector3 {
+	d := vec.SubFV3(b, a)
+	d.Multiply(t)
+	return vec.AddFV3(a, d)
+}
+
+func fixClosestPointOnSegment(p, a, b vec.FixVector3) vec.FixVector3 {
+	d := vec.SubFV3(b, a)
+	dd := fixDot(d, d)
+	if dd == 0 {
+		return a
+	}
+	return fixLerp(a, b, fixClamp(fixDot(vec.SubFV3(p, a), d).Div(dd), 0, fix64.FixOne))
+}
+
+// fixOnSegment 共线的c是否在ab的包围盒内
+func fixOnSegment(a, b, c vec.FixVector3) bool {
+	return c.X >= min(a.X, b.X) && c.X <= max(a.X, b.X) && c.Y >= min(a.Y, b.Y) && c.Y <= max(a.Y, b.Y) &&
+		c.Z >= min(a.Z, b.Z) && c.Z <= max(a.Z, b.Z)
+}
+
+// pointOnFixSegment p是否在线段ab上
+func pointOnFixSegment(p, a, b vec.FixVector3) bool {
+	return fixIsZero(vec.CrossFV3(vec.SubFV3(b, a), vec.SubFV3(p, a))) && fixOnSegment(a, b, p)
+}
+
+// fixTriangleNormal 三角形的单位法线,三点共线时为0
+func fixTriangleNormal(a, b, c vec.FixVector3) vec.FixVector3 {
+	n := vec.CrossFV3(vec.SubFV3(b, a), vec.SubFV3(c, a))
+	if fixIsZero(n) {
+		return n
+	}
+	return n.Normalized()
+}
+
+// fixClosestPointOnTriangle 三角形上离p最近的点:p在平面上的投影落在三角形内时为投影,否则在三条边上找
+func fixClosestPointOnTriangle(p, a, b, c vec.FixVector3) vec.FixVector3 {
+	if n := fixTriangleNormal(a, b, c); !fixIsZero(n) {
+		off := n
+		off.Multiply(fixDot(vec.SubFV3(p, a), n))
+		q := vec.SubFV3(p, off)
+		// 投影在三条边的同一侧时在三角形内
+		e0 := fixDot(vec.CrossFV3(vec.SubFV3(b, a), vec.SubFV3(q, a)), n)
+		e1 := fixDot(vec.CrossFV3(vec.SubFV3(c, b), vec.SubFV3(q, b)), n)
+		e2 := fixDot(vec.CrossFV3(vec.SubFV3(a, c), vec.SubFV3(q, c)), n)
+		if e0 >= 0 && e1 >= 0 && e2 >= 0 {
+			return q
+		}
+	}
+	best := fixClosestPointOnSegment(p, a, b)
+	for _, e := range [2][2]vec.FixVector3{{b, c}, {c, a}} {
+		if q := fixClosestPointOnSegment(p, e[0], e[1]); fixDistSq(p, q) < fixDistSq(p, best) {
+			best = q
+		}
+	}
+	return best
+}
+
+// fixOverlapOnAxes 分离轴测试:两组点的凸包在每条轴上的投影都重叠时返回true,长度为0的轴跳过
+func fixOverlapOnAxes(a, b []vec.FixVector3, axes ...vec.FixVector3) bool {
+	origin := a[0] //投影前先减去a[0],投影值不超过图形的尺寸
+	for _, axis := range axes {
+		if fixIsZero(axis) {
+			continue
+		}
+		axis = axis.Normalized()
+		minA, maxA := fixProjectPointsAxis(a, origin, axis)
+		minB, maxB := fixProjectPointsAxis(b, origin, axis)
+		if maxA < minB || maxB < minA {
+			return false
+		}
+	}
+	return true
+}
+
+func fixProjectPointsAxis(points []vec.FixVector3, origin, axis vec.FixVector3) (fix64.Fix64, fix64.Fix64) {
+	lo, hi := fix64.FixMax, fix64.FixMin
+	for _, p := range points {
+		proj := fixDot(vec.SubFV3(p, origin), axis)
+		lo = min(lo, proj)
+		hi = max(hi, proj)
+	}
+	return lo, hi
+}
+
+// fixCrossAxes a中每条边与b中每条边的叉乘
+func fixCrossAxes(a, b []vec.FixVector3) []vec.FixVector3 {
+	res := make([]vec.FixVector3, 0, len(a)*len(b))
+	for _, u := range a {
+		for _, v := range b {
+			res = append(res, vec.CrossFV3(u, v))
+		}
+	}
+	return res
+}
+
+// fixBoxAxes 长方体的三条单位轴
+func fixBoxAxes(r *FixOBB) []vec.FixVector3 {
+	return []vec.FixVector3{r.Rotation.Rotate(fixUnitX), r.Rotation.Rotate(fixUnitY), r.Rotation.Rotate(fixUnitZ)}
+}
+
+// fixToLocal p相对长方体中心的局部坐标
+func fixToLocal(p vec.FixVector3, r *FixOBB) vec.FixVector3 {
+	return r.Rotation.Conjugate().Rotate(vec.SubFV3(p, r.Pos))
+}
+
+func fixHalfSize(r *FixOBB) vec.FixVector3 {
+	return vec.FixVector3{X: r.Size.X / 2, Y: r.Size.Y / 2, Z: r.Size.Z / 2}
+}
+
+// fixClampToBox 局部坐标p夹到半边长h的长方体内
+func fixClampToBox(p, h vec.FixVector3) vec.FixVector3 {
+	return vec.FixVector3{X: fixClamp(p.X, -h.X, h.X), Y: fixClamp(p.Y, -h.Y, h.Y), Z: fixClamp(p.Z, -h.Z, h.Z)}
+}
+
+func pointInFixBox(p vec.FixVector3, r *FixOBB) bool {
+	local, h := fixToLocal(p, r), fixHalfSize(r)
+	return local.X.Abs() <= h.X && local.Y.Abs() <= h.Y && local.Z.Abs() <= h.Z
+}
+
+func fixBoxCorners(r *FixOBB) []vec.FixVector3 {
+	h := fixHalfSize(r)
+	res := make([]vec.FixVector3, 0, 8)
+	for i := range 8 {
+		local := h
+		if i&1 != 0 {
+			local.X = -local.X
+		}
+		if i&2 != 0 {
+			local.Y = -local.Y
+		}
+		if i&4 != 0 {
+			local.Z = -local.Z
+		}
+		res = append(res, vec.AddFV3(r.Pos, r.Rotation.Rotate(local)))
+	}
+	return res
+}
+
+// fixBoxIntersectsPoints 长方体与凸多边形(1个点为点,2个点为线段,3个点为三角形)是否相交,edges为多边形的边
+func fixBoxIntersectsPoints(r *FixOBB, pts, edges []vec.FixVector3, normal vec.FixVector3) bool {
+	axes := fixBoxAxes(r)
+	return fixOverlapOnAxes(fixBoxCorners(r), pts, append(append(axes, normal), fixCrossAxes(axes, edges)...)...)
+}
+
+// fixSegmentIntersectsSegment 两线段是否相交:
+// 不平行时分离轴为公垂线和平面内两条边的法线;平行时为方向和垂直于方向的两条轴
+func fixSegmentIntersectsSegment(p1, p2, q1, q2 vec.FixVector3) bool {
+	d1, d2 := vec.SubFV3(p2, p1), vec.SubFV3(q2, q1)
+	if fixIsZero(d1) {
+		return pointOnFixSegment(p1, q1, q2)
+	}
+	if fixIsZero(d2) {
+		return pointOnFixSegment(q1, p1, p2)
+	}
+	a, b := []vec.FixVector3{p1, p2}, []vec.FixVector3{q1, q2}
+	if n := vec.CrossFV3(d1, d2); !fixIsZero(n) {
+		return fixOverlapOnAxes(a, b, n, vec.CrossFV3(n, d1), vec.CrossFV3(n, d2))
+	}
+	w := vec.CrossFV3(d1, vec.SubFV3(q1, p1))
+	return fixOverlapOnAxes(a, b, d1, w, vec.CrossFV3(d1, w))
+}
+
+// fixTriangleIntersectsPoints 三角形与凸多边形(2个点为线段,3个点为三角形)是否相交,
+// 分离轴为两者的法线,边两两叉乘,以及共面时平面内各边的法线
+func fixTriangleIntersectsPoints(tri, pts []vec.FixVector3) bool {
+	n := fixTriangleNormal(tri[0], tri[1], tri[2])
+	edges := fixEdges(tri)
+	other := fixEdges(pts)
+	axes := append([]vec.FixVector3{n}, fixCrossAxes(edges, other)...)
+	axes = append(axes, fixCrossAxes([]vec.FixVector3{n}, append(edges, other...))...)
+	if len(pts) == 3 {
+		axes = append(axes, fixTriangleNormal(pts[0], pts[1], pts[2]))
+	}
+	return fixOverlapOnAxes(tri, pts, axes...)
+}
+
+// fixEdges 多边形的各条边,2个点时只有1条
+func fixEdges(pts []vec.FixVector3) []vec.FixVector3 {
+	if len(pts) == 2 {
+		return []vec.FixVector3{vec.SubFV3(pts[1], pts[0])}
+	}
+	res := make([]vec.FixVector3, 0, len(pts))
+	for i, p := range pts {
+		res = append(res, vec.SubFV3(pts[(i+1)%len(pts)], p))
+	}
+	return res
+}
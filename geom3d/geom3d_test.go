@@ -0,0 +1,280 @@
+package geom3d
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/deminzhang/go-common/fix64"
+	"github.com/deminzhang/go-common/vec"
+)
+
+func fv(x, y, z float64) vec.FixVector3 {
+	return vec.FixVector3{X: fix64.FromFloat(x), Y: fix64.FromFloat(y), Z: fix64.FromFloat(z)}
+}
+
+func point(x, y, z float64) *FixPoint {
+	return &FixPoint{FixBaseShape: FixBaseShape{Pos: fv(x, y, z)}}
+}
+
+func sphere(x, y, z, r float64) *FixSphere {
+	return NewFixSphere(fix64.FromFloat(x), fix64.FromFloat(y), fix64.FromFloat(z), fix64.FromFloat(r))
+}
+
+func box(x, y, z, sx, sy, sz float64) *FixAABB {
+	return &FixAABB{FixBaseShape: FixBaseShape{Pos: fv(x, y, z)}, Size: fv(sx, sy, sz)}
+}
+
+// obb 绕Z轴旋转deg度的长方体
+func obb(x, y, z, sx, sy, sz, deg float64) *FixOBB {
+	rot := vec.AxisAngleFQ(vec.ZAxisFix(), fix64.FromFloat(deg*math.Pi/180))
+	return &FixOBB{FixAABB: *box(x, y, z, sx, sy, sz), Rotation: rot}
+}
+
+func segment(x1, y1, z1, x2, y2, z2 float64) *FixLineSegment {
+	return &FixLineSegment{P1: *point(x1, y1, z1), P2: *point(x2, y2, z2)}
+}
+
+func triangle(a, b, c [3]float64) *FixTriangle {
+	return &FixTriangle{A: *point(a[0], a[1], a[2]), B: *point(b[0], b[1], b[2]), C: *point(c[0], c[1], c[2])}
+}
+
+func TestFixShapes(t *testing.T) {
+	tri := triangle([3]float64{0, 0, 0}, [3]float64{4, 0, 0}, [3]float64{0, 4, 0})
+	for i, c := range []struct {
+		a, b IFixShape
+		want bool
+	}{
+		{point(1, 2, 3), point(1, 2, 3), true}, {point(1, 2, 3), point(1, 2, 3.5), false},
+		{point(1, 1, 1), sphere(0, 0, 0, 2), true}, {point(2, 0, 0.5), sphere(0, 0, 0, 2), false},
+		{point(1, 1, 1), box(0, 0, 0, 2, 2, 2), true}, {point(1, 1, 1.5), box(0, 0, 0, 2, 2, 2), false},
+		{point(1.3, 0, 0), obb(0, 0, 0, 2, 2, 2, 45), true}, {point(1, 1, 0), obb(0, 0, 0, 2, 2, 2, 45), false},
+		{point(1, 1, 0), segment(0, 0, 0, 2, 2, 0), true}, {point(1, 1, 0.5), segment(0, 0, 0, 2, 2, 0), false},
+		{point(1, 1, 0), tri, true}, {point(3, 3, 0), tri, false}, {point(1, 1, 0.25), tri, false},
+		{sphere(0, 0, 0, 1), sphere(1.5, 0, 0, 1), true}, {sphere(0, 0, 0, 1), sphere(2, 0, 0.5, 1), false},
+		{sphere(2, 2, 2, 1.8), box(0, 0, 0, 2, 2, 2), true}, {sphere(2, 2, 2, 1.7), box(0, 0, 0, 2, 2, 2), false},
+		{sphere(1.6, 0, 0, 0.5), obb(0, 0, 0, 2, 2, 2, 45), true}, {sphere(1.6, 1.6, 0, 1), obb(0, 0, 0, 2, 2, 2, 45), false},
+		{sphere(1, 1, 1, 1), segment(-5, 1, 0.5, 5, 1, 0.5), true}, {sphere(1, 1, 1, 1), segment(-5, 1, -0.5, 5, 1, -0.5), false},
+		{sphere(1, 1, 1, 1.5), tri, true}, {sphere(1, 1, 1, 0.5), tri, false}, {sphere(3, 3, 0, 1.5), tri, true}, {sphere(3, 3, 0, 1.4), tri, false},
+		{box(0, 0, 0, 2, 2, 2), box(1.5, 1.5, 1.5, 2, 2, 2), true}, {box(0, 0, 0, 2, 2, 2), box(2.5, 0, 0, 2, 2, 2), false},
+		{box(0, 0, 0, 2, 2, 2), obb(2.3, 0, 0, 2, 2, 2, 45), true}, {box(0, 0, 0, 2, 2, 2), obb(2.5, 0, 0, 2, 2, 2, 45), false},
+		// 棱与棱相交,只有叉乘轴能分开
+		{obb(0, 0, 0, 2, 2, 2, 45), &FixOBB{FixAABB: *box(3.2, 0, 0, 2, 2, 2), Rotation: vec.AxisAngleFQ(vec.YAxisFix(), fix64.FromFloat(math.Pi/4))}, false},
+		{obb(0, 0, 0, 2, 2, 2, 45), &FixOBB{FixAABB: *box(2.6, 0, 0, 2, 2, 2), Rotation: vec.AxisAngleFQ(vec.YAxisFix(), fix64.FromFloat(math.Pi/4))}, true},
+		{box(0, 0, 0, 2, 2, 2), segment(-5, 0.5, 0.5, 5, 0.5, 0.5), true}, {box(0, 0, 0, 2, 2, 2), segment(-5, 0, 1.5, 5, 0, 0.5), true},
+		{box(0, 0, 0, 2, 2, 2), segment(-5, 0, 2.5, 5, 0, 1.5), false}, {obb(0, 0, 0, 2, 2, 2, 45), segment(1, 1, -5, 1, 1, 5), false},
+		{box(0, 0, 0, 2, 2, 2), tri, true}, {box(3.5, 3.5, 0, 2, 2, 2), tri, false}, {box(1, 1, 3, 2, 2, 2), tri, false},
+		{box(1, 1, 0, 0.5, 0.5, 0.5), tri, true}, {box(1, 1, 0, 10, 10, 1), tri, true},
+		{segment(0, 0, 0, 2, 2, 0), segment(0, 2, 0, 2, 0, 0), true}, {segment(0, 0, 0, 2, 2, 0), segment(0, 2, 1, 2, 0, 1), false},
+		{segment(0, 0, 0, 2, 2, 2), segment(1, 1, 1, 3, 3, 3), true}, {segment(0, 0, 0, 1, 1, 1), segment(2, 2, 2, 3, 3, 3), false},
+		{segment(0, 0, 0, 2, 0, 0), segment(0, 1, 0, 2, 1, 0), false}, {segment(0, 0, 0, 2, 0, 0), segment(1, -1, 1, 1, 1, 1), false},
+		{segment(1, 1, -1, 1, 1, 1), tri, true}, {segment(3, 3, -1, 3, 3, 1), tri, false}, {segment(1, 1, 0.5, 1, 1, 2), tri, false},
+		{segment(-1, 1, 0, 5, 1, 0), tri, true}, {segment(-1, 5, 0, 5, 5, 0), tri, false},
+		{tri, triangle([3]float64{1, 1, -1}, [3]float64{1, 1, 1}, [3]float64{5, 5, 0}), true},
+		{tri, triangle([3]float64{3, 3, -1}, [3]float64{3, 3, 1}, [3]float64{5, 5, 0}), false},
+		{tri, triangle([3]float64{1, 1, 0}, [3]float64{5, 1, 0}, [3]float64{1, 5, 0}), true},
+		{tri, triangle([3]float64{3, 3, 0}, [3]float64{5, 3, 0}, [3]float64{3, 5, 0}), false},
+		{tri, triangle([3]float64{1, 1, 1}, [3]float64{2, 1, 1}, [3]float64{1, 2, 1}), false},
+	} {
+		if got := c.a.Intersects(c.b); got != c.want {
+			t.Errorf("case %d: %T.Intersects(%T) = %v", i, c.a, c.b, got)
+		}
+		if got := c.b.Intersects(c.a); got != c.want {
+			t.Errorf("case %d: %T.Intersects(%T) = %v", i, c.b, c.a, got)
+		}
+	}
+}
+
+// shapeParams 随机图形参数,取1/16的整数倍,Fix64能精确表示;旋转为Fix64的四元数,参考实现用同一组值
+type shapeParams struct {
+	kind int
+	v    [9]float64
+	rot  vec.FixQuaternion
+}
+
+const shapeKinds = 6
+
+func randShapeParams(r *rand.Rand) shapeParams {
+	coord := func() float64 { return float64(r.Intn(193)-96) / 16 }
+	size := func() float64 { return float64(r.Intn(89)+8) / 16 }
+	p := shapeParams{kind: r.Intn(shapeKinds), rot: vec.IdentityFQ()}
+	switch p.kind {
+	case 0: // Point
+		p.v = [9]float64{coord(), coord(), coord()}
+	case 1: // Sphere
+		p.v = [9]float64{coord(), coord(), coord(), size()}
+	case 2, 3: // AABB, OBB
+		p.v = [9]float64{coord(), coord(), coord(), size(), size(), size()}
+		if p.kind == 3 {
+			angle := func() fix64.Fix64 { return fix64.FromFloat(r.Float64() * 2 * math.Pi) }
+			p.rot = vec.EulerFQ(angle(), angle(), angle())
+		}
+	case 4: // LineSegment
+		p.v = [9]float64{coord(), coord(), coord(), coord(), coord(), coord()}
+	case 5: // Triangle
+		for i := range p.v {
+			p.v[i] = coord()
+		}
+	}
+	return p
+}
+
+func (p shapeParams) fixShape() IFixShape {
+	v := p.v
+	switch p.kind {
+	case 0:
+		return point(v[0], v[1], v[2])
+	case 1:
+		return sphere(v[0], v[1], v[2], v[3])
+	case 2:
+		return box(v[0], v[1], v[2], v[3], v[4], v[5])
+	case 3:
+		return &FixOBB{FixAABB: *box(v[0], v[1], v[2], v[3], v[4], v[5]), Rotation: p.rot}
+	case 4:
+		return segment(v[0], v[1], v[2], v[3], v[4], v[5])
+	}
+	return triangle([3]float64{v[0], v[1], v[2]}, [3]float64{v[3], v[4], v[5]}, [3]float64{v[6], v[7], v[8]})
+}
+
+// 以下为float64的参考实现:各图形上离x最近的点,交替投影求两个凸集的距离
+
+type v3 [3]float64
+
+func (a v3) add(b v3) v3          { return v3{a[0] + b[0], a[1] + b[1], a[2] + b[2]} }
+func (a v3) sub(b v3) v3          { return v3{a[0] - b[0], a[1] - b[1], a[2] - b[2]} }
+func (a v3) mul(k float64) v3     { return v3{a[0] * k, a[1] * k, a[2] * k} }
+func (a v3) dot(b v3) float64     { return a[0]*b[0] + a[1]*b[1] + a[2]*b[2] }
+func (a v3) dist(b v3) float64    { d := a.sub(b); return math.Sqrt(d.dot(d)) }
+func clamp01(t float64) float64   { return min(max(t, 0), 1) }
+func (p shapeParams) at(i int) v3 { return v3{p.v[i], p.v[i+1], p.v[i+2]} }
+
+func refSegment(x, a, b v3) v3 {
+	d := b.sub(a)
+	if dd := d.dot(d); dd > 0 {
+		return a.add(d.mul(clamp01(x.sub(a).dot(d) / dd)))
+	}
+	return a
+}
+
+// refTriangle 《Real-Time Collision Detection》5.1.5
+func refTriangle(p, a, b, c v3) v3 {
+	ab, ac, ap := b.sub(a), c.sub(a), p.sub(a)
+	d1, d2 := ab.dot(ap), ac.dot(ap)
+	if d1 <= 0 && d2 <= 0 {
+		return a
+	}
+	bp := p.sub(b)
+	d3, d4 := ab.dot(bp), ac.dot(bp)
+	if d3 >= 0 && d4 <= d3 {
+		return b
+	}
+	vc := d1*d4 - d3*d2
+	if vc <= 0 && d1 >= 0 && d3 <= 0 {
+		return a.add(ab.mul(d1 / (d1 - d3)))
+	}
+	cp := p.sub(c)
+	d5, d6 := ab.dot(cp), ac.dot(cp)
+	if d6 >= 0 && d5 <= d6 {
+		return c
+	}
+	vb := d5*d2 - d1*d6
+	if vb <= 0 && d2 >= 0 && d6 <= 0 {
+		return a.add(ac.mul(d2 / (d2 - d6)))
+	}
+	va := d3*d6 - d5*d4
+	if va <= 0 && d4-d3 >= 0 && d5-d6 >= 0 {
+		return b.add(c.sub(b).mul((d4 - d3) / ((d4 - d3) + (d5 - d6))))
+	}
+	if s := va + vb + vc; s != 0 {
+		return a.add(ab.mul(vb / s)).add(ac.mul(vc / s))
+	}
+	return refSegment(p, a, b) //三点共线
+}
+
+func (p shapeParams) closest(x v3) v3 {
+	c := p.at(0)
+	switch p.kind {
+	case 0:
+		return c
+	case 1:
+		if d := x.dist(c); d > p.v[3] {
+			return c.add(x.sub(c).mul(p.v[3] / d))
+		}
+		return x
+	case 2, 3:
+		m := p.rot.Matrix3()
+		var axes [3]v3
+		for i := range axes {
+			axes[i] = v3{m[0][i].Float64(), m[1][i].Float64(), m[2][i].Float64()}
+		}
+		res := c
+		for i, axis := range axes {
+			h := p.v[3+i] / 2
+			res = res.add(axis.mul(min(max(x.sub(c).dot(axis), -h), h)))
+		}
+		return res
+	case 4:
+		return refSegment(x, c, p.at(3))
+	}
+	return refTriangle(x, c, p.at(3), p.at(6))
+}
+
+// refDistance 交替投影到两个凸集上,收敛到最近的一对点;两个集合接近平行时收敛很慢,未收敛时ok为false
+func refDistance(a, b shapeParams) (d float64, ok bool) {
+	x := a.at(0)
+	for range 2000 {
+		next := a.closest(b.closest(x))
+		if next.dist(x) < 1e-12 {
+			return next.dist(b.closest(next)), true
+		}
+		x = next
+	}
+	return 0, false
+}
+
+// TestFixShapesMatchReference 随机图形对,与float64参考实现的距离比较:距离约为0时相交,明显大于0时不相交,其余跳过
+func TestFixShapesMatchReference(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var checked [shapeKinds][shapeKinds][2]int
+	for range 50000 {
+		pa, pb := randShapeParams(r), randShapeParams(r)
+		fa, fb := pa.fixShape(), pb.fixShape()
+		got := fa.Intersects(fb)
+		if got != fb.Intersects(fa) {
+			t.Fatalf("%T/%T not symmetric: %+v %+v", fa, fb, pa, pb)
+		}
+		d, ok := refDistance(pa, pb)
+		if !ok || d > 1e-6 && d < 1e-3 {
+			continue
+		}
+		if want := d <= 1e-6; got != want {
+			t.Fatalf("%T.Intersects(%T) = %v, distance %v: %+v %+v", fa, fb, got, d, pa, pb)
+		}
+		checked[pa.kind][pb.kind][btoi(got)]++
+	}
+	// 体积为0的图形之间几乎不会随机相交,只要求有不相交的样本
+	for i := range checked {
+		for j := range checked[i] {
+			if checked[i][j][0] == 0 || i > 0 && j > 0 && (i < 4 || j < 4) && checked[i][j][1] == 0 {
+				t.Errorf("kinds %d/%d checked %v", i, j, checked[i][j])
+			}
+		}
+	}
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func TestMove(t *testing.T) {
+	s := segment(0, 0, 0, 1, 1, 1)
+	s.Move(fv(1, 2, 3))
+	b := box(0, 0, 0, 1, 1, 1)
+	b.Move(fv(-1, 0, 0))
+	if s.P1.Pos != fv(1, 2, 3) || s.P2.Pos != fv(2, 3, 4) || b.Pos != fv(-1, 0, 0) {
+		t.Errorf("move: %v %v %v", s.P1.Pos, s.P2.Pos, b.Pos)
+	}
+}
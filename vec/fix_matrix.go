@@ -0,0 +1,226 @@
+package vec
+
+import (
+	. "github.com/deminzhang/go-common/fix64"
+)
+
+// FixMatrix3x3 行优先,M[行][列],右乘列向量: v' = M*v
+// 角度均为弧度
+type FixMatrix3x3 [3][3]Fix64
+
+// FixMatrix4x4 行优先,M[行][列],平移在第4列
+type FixMatrix4x4 [4][4]Fix64
+
+func IdentityFM3() FixMatrix3x3 {
+	return FixMatrix3x3{{FixOne, 0, 0}, {0, FixOne, 0}, {0, 0, FixOne}}
+}
+
+// RotationXFM3 绕X轴旋转
+func RotationXFM3(angle Fix64) FixMatrix3x3 {
+	s, c := angle.SinCos()
+	return FixMatrix3x3{{FixOne, 0, 0}, {0, c, -s}, {0, s, c}}
+}
+
+// RotationYFM3 绕Y轴旋转
+func RotationYFM3(angle Fix64) FixMatrix3x3 {
+	s, c := angle.SinCos()
+	return FixMatrix3x3{{c, 0, s}, {0, FixOne, 0}, {-s, 0, c}}
+}
+
+// RotationZFM3 绕Z轴旋转
+func RotationZFM3(angle Fix64) FixMatrix3x3 {
+	s, c := angle.SinCos()
+	return FixMatrix3x3{{c, -s, 0}, {s, c, 0}, {0, 0, FixOne}}
+}
+
+// AxisAngleFM3 绕单位向量axis旋转angle
+func AxisAngleFM3(axis FixVector3, angle Fix64) FixMatrix3x3 {
+	return AxisAngleFQ(axis, angle).Matrix3()
+}
+
+// EulerFM3 欧拉角,与Unity相同依次绕Z,X,Y轴旋转,即 Ry*Rx*Rz
+func EulerFM3(x, y, z Fix64) FixMatrix3x3 {
+	return RotationYFM3(y).Mul(RotationXFM3(x)).Mul(RotationZFM3(z))
+}
+
+func (m FixMatrix3x3) Mul(o FixMatrix3x3) FixMatrix3x3 {
+	var r FixMatrix3x3
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			r[i][j] = m[i][0].Mul(o[0][j]) + m[i][1].Mul(o[1][j]) + m[i][2].Mul(o[2][j])
+		}
+	}
+	return r
+}
+
+// MulVec 变换向量
+func (m FixMatrix3x3) MulVec(v FixVector3) FixVector3 {
+	return FixVector3{
+		X: m[0][0].Mul(v.X) + m[0][1].Mul(v.Y) + m[0][2].Mul(v.Z),
+		Y: m[1][0].Mul(v.X) + m[1][1].Mul(v.Y) + m[1][2].Mul(v.Z),
+		Z: m[2][0].Mul(v.X) + m[2][1].Mul(v.Y) + m[2][2].Mul(v.Z),
+	}
+}
+
+func (m FixMatrix3x3) Transpose() FixMatrix3x3 {
+	var r FixMatrix3x3
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			r[i][j] = m[j][i]
+		}
+	}
+	return r
+}
+
+func (m FixMatrix3x3) Determinant() Fix64 {
+	return m[0][0].Mul(m[1][1].Mul(m[2][2])-m[1][2].Mul(m[2][1])) -
+		m[0][1].Mul(m[1][0].Mul(m[2][2])-m[1][2].Mul(m[2][0])) +
+		m[0][2].Mul(m[1][0].Mul(m[2][1])-m[1][1].Mul(m[2][0]))
+}
+
+// Inverse 逆矩阵,不可逆时返回false;纯旋转矩阵用Transpose更快更准
+func (m FixMatrix3x3) Inverse() (FixMatrix3x3, bool) {
+	det := m.Determinant()
+	if det == 0 {
+		return FixMatrix3x3{}, false
+	}
+	// 伴随矩阵/行列式
+	var r FixMatrix3x3
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			a, b := (j+1)%3, (j+2)%3
+			c, d := (i+1)%3, (i+2)%3
+			r[i][j] = (m[a][c].Mul(m[b][d]) - m[a][d].Mul(m[b][c])).Div(det)
+		}
+	}
+	return r, true
+}
+
+// Matrix4 扩展为无平移的4x4矩阵
+func (m FixMatrix3x3) Matrix4() FixMatrix4x4 {
+	r := IdentityFM4()
+	for i := 0; i < 3; i++ {
+		copy(r[i][:3], m[i][:])
+	}
+	return r
+}
+
+func IdentityFM4() FixMatrix4x4 {
+	return FixMatrix4x4{{FixOne, 0, 0, 0}, {0, FixOne, 0, 0}, {0, 0, FixOne, 0}, {0, 0, 0, FixOne}}
+}
+
+// TranslationFM4 平移矩阵
+func TranslationFM4(t FixVector3) FixMatrix4x4 {
+	r := IdentityFM4()
+	r[0][3], r[1][3], r[2][3] = t.X, t.Y, t.Z
+	return r
+}
+
+// TRSFM4 依次缩放,旋转,平移
+func TRSFM4(t FixVector3, r FixQuaternion, s FixVector3) FixMatrix4x4 {
+	m := r.Matrix3()
+	ret := IdentityFM4()
+	for i := 0; i < 3; i++ {
+		ret[i][0] = m[i][0].Mul(s.X)
+		ret[i][1] = m[i][1].Mul(s.Y)
+		ret[i][2] = m[i][2].Mul(s.Z)
+	}
+	ret[0][3], ret[1][3], ret[2][3] = t.X, t.Y, t.Z
+	return ret
+}
+
+func (m FixMatrix4x4) Mul(o FixMatrix4x4) FixMatrix4x4 {
+	var r FixMatrix4x4
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			r[i][j] = m[i][0].Mul(o[0][j]) + m[i][1].Mul(o[1][j]) + m[i][2].Mul(o[2][j]) + m[i][3].Mul(o[3][j])
+		}
+	}
+	return r
+}
+
+// MulPoint 变换点(w=1),含平移
+func (m FixMatrix4x4) MulPoint(v FixVector3) FixVector3 {
+	return FixVector3{
+		X: m[0][0].Mul(v.X) + m[0][1].Mul(v.Y) + m[0][2].Mul(v.Z) + m[0][3],
+		Y: m[1][0].Mul(v.X) + m[1][1].Mul(v.Y) + m[1][2].Mul(v.Z) + m[1][3],
+		Z: m[2][0].Mul(v.X) + m[2][1].Mul(v.Y) + m[2][2].Mul(v.Z) + m[2][3],
+	}
+}
+
+// MulDir 变换方向(w=0),不含平移
+func (m FixMatrix4x4) MulDir(v FixVector3) FixVector3 {
+	return m.Matrix3().MulVec(v)
+}
+
+// Matrix3 左上角3x3部分
+func (m FixMatrix4x4) Matrix3() FixMatrix3x3 {
+	var r FixMatrix3x3
+	for i := 0; i < 3; i++ {
+		copy(r[i][:], m[i][:3])
+	}
+	return r
+}
+
+func (m FixMatrix4x4) Transpose() FixMatrix4x4 {
+	var r FixMatrix4x4
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			r[i][j] = m[j][i]
+		}
+	}
+	return r
+}
+
+// Inverse 逆矩阵,不可逆时返回false
+func (m FixMatrix4x4) Inverse() (FixMatrix4x4, bool) {
+	// 按前两行和后两行的2x2子式展开
+	s0 := m[0][0].Mul(m[1][1]) - m[1][0].Mul(m[0][1])
+	s1 := m[0][0].Mul(m[1][2]) - m[1][0].Mul(m[0][2])
+	s2 := m[0][0].Mul(m[1][3]) - m[1][0].Mul(m[0][3])
+	s3 := m[0][1].Mul(m[1][2]) - m[1][1].Mul(m[0][2])
+	s4 := m[0][1].Mul(m[1][3]) - m[1][1].Mul(m[0][3])
+	s5 := m[0][2].Mul(m[1][3]) - m[1][2].Mul(m[0][3])
+	c5 := m[2][2].Mul(m[3][3]) - m[3][2].Mul(m[2][3])
+	c4 := m[2][1].Mul(m[3][3]) - m[3][1].Mul(m[2][3])
+	c3 := m[2][1].Mul(m[3][2]) - m[3][1].Mul(m[2][2])
+	c2 := m[2][0].Mul(m[3][3]) - m[3][0].Mul(m[2][3])
+	c1 := m[2][0].Mul(m[3][2]) - m[3][0].Mul(m[2][2])
+	c0 := m[2][0].Mul(m[3][1]) - m[3][0].Mul(m[2][1])
+	det := s0.Mul(c5) - s1.Mul(c4) + s2.Mul(c3) + s3.Mul(c2) - s4.Mul(c1) + s5.Mul(c0)
+	if det == 0 {
+		return FixMatrix4x4{}, false
+	}
+	adj := FixMatrix4x4{
+		{
+			m[1][1].Mul(c5) - m[1][2].Mul(c4) + m[1][3].Mul(c3),
+			-m[0][1].Mul(c5) + m[0][2].Mul(c4) - m[0][3].Mul(c3),
+			m[3][1].Mul(s5) - m[3][2].Mul(s4) + m[3][3].Mul(s3),
+			-m[2][1].Mul(s5) + m[2][2].Mul(s4) - m[2][3].Mul(s3),
+		},
+		{
+			-m[1][0].Mul(c5) + m[1][2].Mul(c2) - m[1][3].Mul(c1),
+			m[0][0].Mul(c5) - m[0][2].Mul(c2) + m[0][3].Mul(c1),
+			-m[3][0].Mul(s5) + m[3][2].Mul(s2) - m[3][3].Mul(s1),
+			m[2][0].Mul(s5) - m[2][2].Mul(s2) + m[2][3].Mul(s1),
+		},
+		{
+			m[1][0].Mul(c4) - m[1][1].Mul(c2) + m[1][3].Mul(c0),
+			-m[0][0].Mul(c4) + m[0][1].Mul(c2) - m[0][3].Mul(c0),
+			m[3][0].Mul(s4) - m[3][1].Mul(s2) + m[3][3].Mul(s0),
+			-m[2][0].Mul(s4) + m[2][1].Mul(s2) - m[2][3].Mul(s0),
+		},
+		{
+			-m[1][0].Mul(c3) + m[1][1].Mul(c1) - m[1][2].Mul(c0),
+			m[0][0].Mul(c3) - m[0][1].Mul(c1) + m[0][2].Mul(c0),
+			-m[3][0].Mul(s3) + m[3][1].Mul(s1) - m[3][2].Mul(s0),
+			m[2][0].Mul(s3) - m[2][1].Mul(s1) + m[2][2].Mul(s0),
+		},
+	}
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			adj[i][j] = adj[i][j].Div(det)
+		}
+	}
+	return adj, true
+}
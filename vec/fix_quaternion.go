@@ -0,0 +1,132 @@
+package vec
+
+import (
+	. "github.com/deminzhang/go-common/fix64"
+)
+
+// FixQuaternion 旋转四元数,W为实部,角度均为弧度
+type FixQuaternion struct {
+	X Fix64 `json:"x"`
+	Y Fix64 `json:"y"`
+	Z Fix64 `json:"z"`
+	W Fix64 `json:"w"`
+}
+
+func NewFixQuaternion(x, y, z, w Fix64) FixQuaternion {
+	return FixQuaternion{X: x, Y: y, Z: z, W: w}
+}
+
+func IdentityFQ() FixQuaternion {
+	return FixQuaternion{W: FixOne}
+}
+
+// AxisAngleFQ 绕单位向量axis旋转angle
+func AxisAngleFQ(axis FixVector3, angle Fix64) FixQuaternion {
+	half := angle / 2
+	s, c := half.SinCos()
+	return FixQuaternion{X: axis.X.Mul(s), Y: axis.Y.Mul(s), Z: axis.Z.Mul(s), W: c}
+}
+
+// EulerFQ 欧拉角,与Unity相同依次绕Z,X,Y轴旋转
+func EulerFQ(x, y, z Fix64) FixQuaternion {
+	qx := AxisAngleFQ(XAxisFix(), x)
+	qy := AxisAngleFQ(YAxisFix(), y)
+	qz := AxisAngleFQ(ZAxisFix(), z)
+	return qy.Mul(qx).Mul(qz)
+}
+
+// Mul 先旋转o再旋转q
+func (q FixQuaternion) Mul(o FixQuaternion) FixQuaternion {
+	return FixQuaternion{
+		X: q.W.Mul(o.X) + q.X.Mul(o.W) + q.Y.Mul(o.Z) - q.Z.Mul(o.Y),
+		Y: q.W.Mul(o.Y) - q.X.Mul(o.Z) + q.Y.Mul(o.W) + q.Z.Mul(o.X),
+		Z: q.W.Mul(o.Z) + q.X.Mul(o.Y) - q.Y.Mul(o.X) + q.Z.Mul(o.W),
+		W: q.W.Mul(o.W) - q.X.Mul(o.X) - q.Y.Mul(o.Y) - q.Z.Mul(o.Z),
+	}
+}
+
+func (q FixQuaternion) Dot(o FixQuaternion) Fix64 {
+	return q.X.Mul(o.X) + q.Y.Mul(o.Y) + q.Z.Mul(o.Z) + q.W.Mul(o.W)
+}
+
+func (q FixQuaternion) SqrMagnitude() Fix64 {
+	return q.Dot(q)
+}
+
+// Normalized 单位化,零四元数返回单位四元数
+func (q FixQuaternion) Normalized() FixQuaternion {
+	l := q.SqrMagnitude().Sqrt()
+	if l == 0 {
+		return IdentityFQ()
+	}
+	return FixQuaternion{X: q.X.Div(l), Y: q.Y.Div(l), Z: q.Z.Div(l), W: q.W.Div(l)}
+}
+
+// Conjugate 共轭,单位四元数的逆
+func (q FixQuaternion) Conjugate() FixQuaternion {
+	return FixQuaternion{X: -q.X, Y: -q.Y, Z: -q.Z, W: q.W}
+}
+
+// Inverse 逆,零四元数返回零
+func (q FixQuaternion) Inverse() FixQuaternion {
+	n := q.SqrMagnitude()
+	if n == 0 {
+		return FixQuaternion{}
+	}
+	return FixQuaternion{X: -q.X.Div(n), Y: -q.Y.Div(n), Z: -q.Z.Div(n), W: q.W.Div(n)}
+}
+
+// Rotate 旋转向量,q需为单位四元数
+func (q FixQuaternion) Rotate(v FixVector3) FixVector3 {
+	// v' = v + 2w(u×v) + 2u×(u×v), u为虚部
+	u := FixVector3{X: q.X, Y: q.Y, Z: q.Z}
+	t := CrossFV3(u, v)
+	t.Multiply(2 * FixOne)
+	r := CrossFV3(u, t)
+	t.Multiply(q.W)
+	return AddFV3(AddFV3(v, t), r)
+}
+
+// Matrix3 对应的旋转矩阵,q需为单位四元数
+func (q FixQuaternion) Matrix3() FixMatrix3x3 {
+	xx, yy, zz := q.X.Mul(q.X), q.Y.Mul(q.Y), q.Z.Mul(q.Z)
+	xy, xz, yz := q.X.Mul(q.Y), q.X.Mul(q.Z), q.Y.Mul(q.Z)
+	wx, wy, wz := q.W.Mul(q.X), q.W.Mul(q.Y), q.W.Mul(q.Z)
+	return FixMatrix3x3{
+		{FixOne - 2*(yy+zz), 2 * (xy - wz), 2 * (xz + wy)},
+		{2 * (xy + wz), FixOne - 2*(xx+zz), 2 * (yz - wx)},
+		{2 * (xz - wy), 2 * (yz + wx), FixOne - 2*(xx+yy)},
+	}
+}
+
+// Matrix4 对应的无平移4x4矩阵
+func (q FixQuaternion) Matrix4() FixMatrix4x4 {
+	return q.Matrix3().Matrix4()
+}
+
+// slerpThreshold 夹角余弦大于此值时改用线性插值,避免除以很小的sin
+var slerpThreshold = FixOne - FixOne>>10
+
+// SlerpFQ 球面线性插值,走最短路径,t为[0,1]
+func SlerpFQ(a, b FixQuaternion, t Fix64) FixQuaternion {
+	d := a.Dot(b)
+	if d < 0 {
+		b = FixQuaternion{X: -b.X, Y: -b.Y, Z: -b.Z, W: -b.W}
+		d = -d
+	}
+	var wa, wb Fix64
+	if d > slerpThreshold {
+		wa, wb = FixOne-t, t
+	} else {
+		theta := d.Acos()
+		sin := theta.Sin()
+		wa = ((FixOne - t).Mul(theta)).Sin().Div(sin)
+		wb = t.Mul(theta).Sin().Div(sin)
+	}
+	return FixQuaternion{
+		X: a.X.Mul(wa) + b.X.Mul(wb),
+		Y: a.Y.Mul(wa) + b.Y.Mul(wb),
+		Z: a.Z.Mul(wa) + b.Z.Mul(wb),
+		W: a.W.Mul(wa) + b.W.Mul(wb),
+	}.Normalized()
+}
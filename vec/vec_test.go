@@ -0,0 +1,80 @@
+package vec
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/deminzhang/go-common/fix64"
+)
+
+func fv3(x, y, z float64) FixVector3 {
+	return FixVector3{X: FromFloat(x), Y: FromFloat(y), Z: FromFloat(z)}
+}
+
+func checkFV3(t *testing.T, name string, got, want FixVector3, tol float64) {
+	t.Helper()
+	if math.Abs(got.X.Float64()-want.X.Float64()) > tol ||
+		math.Abs(got.Y.Float64()-want.Y.Float64()) > tol ||
+		math.Abs(got.Z.Float64()-want.Z.Float64()) > tol {
+		t.Errorf("%s = %s, want %s", name, got.Float(), want.Float())
+	}
+}
+
+func TestFixRotation(t *testing.T) {
+	half := FixHalfPi
+	v := fv3(1, 2, 3)
+	// 绕Z轴90度: (x,y,z) -> (-y,x,z)
+	checkFV3(t, "RotationZ", RotationZFM3(half).MulVec(v), fv3(-2, 1, 3), 1e-7)
+	checkFV3(t, "AxisAngleFQ Z", AxisAngleFQ(ZAxisFix(), half).Rotate(v), fv3(-2, 1, 3), 1e-7)
+	checkFV3(t, "RotationX", RotationXFM3(half).MulVec(v), fv3(1, -3, 2), 1e-7)
+	checkFV3(t, "RotationY", RotationYFM3(half).MulVec(v), fv3(3, 2, -1), 1e-7)
+
+	// 四元数,矩阵,欧拉角三种方式结果一致
+	x, y, z := FromFloat(0.3), FromFloat(-1.1), FromFloat(2.0)
+	q := EulerFQ(x, y, z)
+	m := EulerFM3(x, y, z)
+	checkFV3(t, "EulerFQ vs EulerFM3", q.Rotate(v), m.MulVec(v), 1e-7)
+	checkFV3(t, "Matrix3", q.Matrix3().MulVec(v), m.MulVec(v), 1e-7)
+	axis := fv3(1, 1, 0)
+	axis.Normalize()
+	checkFV3(t, "AxisAngleFM3", AxisAngleFM3(axis, x).MulVec(v), AxisAngleFQ(axis, x).Rotate(v), 1e-7)
+
+	// 逆与转置
+	checkFV3(t, "Inverse", q.Inverse().Rotate(q.Rotate(v)), v, 1e-7)
+	checkFV3(t, "Conjugate", q.Conjugate().Mul(q).Rotate(v), v, 1e-7)
+	mi, ok := m.Inverse()
+	if !ok {
+		t.Fatalf("rotation matrix not invertible")
+	}
+	checkFV3(t, "FM3 Inverse", mi.MulVec(m.MulVec(v)), v, 1e-7)
+	checkFV3(t, "FM3 Transpose", m.Transpose().MulVec(m.MulVec(v)), v, 1e-7)
+	if _, ok := (FixMatrix3x3{}).Inverse(); ok {
+		t.Errorf("zero matrix invertible")
+	}
+
+	trs := TRSFM4(fv3(10, -5, 2), q, fv3(2, 2, 2))
+	p := trs.MulPoint(v)
+	want := q.Rotate(fv3(2, 4, 6))
+	checkFV3(t, "TRS", p, AddFV3(want, fv3(10, -5, 2)), 1e-7)
+	inv, ok := trs.Inverse()
+	if !ok {
+		t.Fatalf("TRS not invertible")
+	}
+	checkFV3(t, "FM4 Inverse", inv.MulPoint(p), v, 1e-7)
+	checkFV3(t, "FM4 Mul", trs.Mul(inv).MulPoint(v), v, 1e-7)
+	checkFV3(t, "FM4 Transpose", trs.Transpose().Transpose().MulDir(v), trs.MulDir(v), 0)
+}
+
+func TestSlerpFQ(t *testing.T) {
+	a := IdentityFQ()
+	b := AxisAngleFQ(ZAxisFix(), FixPi.Mul(FromFloat(0.8)))
+	v := fv3(1, 0, 0)
+	for _, f := range []float64{0, 0.25, 0.5, 1} {
+		got := SlerpFQ(a, b, FromFloat(f)).Rotate(v)
+		ang := math.Pi * 0.8 * f
+		checkFV3(t, "SlerpFQ", got, fv3(math.Cos(ang), math.Sin(ang), 0), 1e-7)
+	}
+	// 几乎相同的两个四元数
+	c := AxisAngleFQ(ZAxisFix(), FromFloat(1e-4))
+	checkFV3(t, "SlerpFQ near", SlerpFQ(a, c, FixOne/2).Rotate(v), fv3(1, 5e-5, 0), 1e-7)
+}